@@ -4,24 +4,70 @@ import (
 	"fmt"
 	"os"
 
+	"go-typ0/internal/compare"
+	"go-typ0/internal/db"
+	"go-typ0/internal/drill"
+	"go-typ0/internal/goal"
+	"go-typ0/internal/history"
+	"go-typ0/internal/learn"
+	"go-typ0/internal/lesson"
+	"go-typ0/internal/pb"
+	"go-typ0/internal/profile"
 	"go-typ0/internal/race"
+	"go-typ0/internal/rating"
+	"go-typ0/internal/stats"
+	"go-typ0/internal/storage"
 
 	"github.com/spf13/cobra"
 )
 
+var profileName string
+
 var rootCmd = &cobra.Command{
 	Use:   "typ0",
 	Short: "A CLI typing practice tool",
 	Long:  `An interactive CLI tool for typing practice with real-time feedback and statistics.`,
+	// main prints the error itself.
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if profileName != "" {
+			return storage.UseProfile(profileName)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("🏁 Welcome to Typ0!")
+		// Goals are a nice extra here; problems reading them show up in
+		// "typ0 goal" instead.
+		if summary, err := goal.Summary(); err == nil && summary != "" {
+			fmt.Println(summary)
+		}
 		fmt.Println("Start typing: typ0 race")
 		fmt.Println("Show help: typ0 --help")
 	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Profile to use for this run (see \"typ0 profile\")")
+
+	race.AddRecorder(history.Attach)
+	race.AddRecorder(pb.Attach)
+	race.AddRecorder(rating.Attach)
+	history.AddImportHook(pb.Imported)
+	history.AddImportHook(rating.Imported)
+
 	rootCmd.AddCommand(race.NewCommand())
+	rootCmd.AddCommand(drill.NewCommand())
+	rootCmd.AddCommand(learn.NewCommand())
+	rootCmd.AddCommand(lesson.NewCommand())
+	rootCmd.AddCommand(history.NewCommand())
+	rootCmd.AddCommand(stats.NewCommand())
+	rootCmd.AddCommand(pb.NewCommand())
+	rootCmd.AddCommand(rating.NewCommand())
+	rootCmd.AddCommand(goal.NewCommand())
+	rootCmd.AddCommand(compare.NewCommand())
+	rootCmd.AddCommand(profile.NewCommand())
+	rootCmd.AddCommand(db.NewCommand())
 }
 
 func main() {
@@ -29,4 +75,4 @@ func main() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,187 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+
+	"github.com/spf13/cobra"
+)
+
+// significance is the p-value below which a difference is reported as
+// significant.
+const significance = 0.05
+
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+type group struct {
+	tag      string
+	wpm      []float64
+	accuracy []float64
+}
+
+func NewCommand() *cobra.Command {
+	var (
+		tags  []string
+		mode  string
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare races with different tags",
+		Long: `Compare the WPM and accuracy of races tagged differently, such as on two
+keyboards, and test whether the differences are statistically significant.
+Tag races with "--tag" when racing. Each tag after the first is compared
+with the first using Welch's t-test. Only races typed to the end count,
+not ones ended early or abandoned, or follow-up retries; compare within
+one mode for like-for-like texts.`,
+		Example: `  typ0 race --tag split
+  typ0 compare --tag laptop --tag split --mode words`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tags, err := race.ParseTags(tags)
+			if err != nil {
+				return err
+			}
+			if len(tags) < 2 {
+				return fmt.Errorf("give at least two tags to compare, e.g. --tag laptop --tag split")
+			}
+
+			query := history.Query{Mode: mode}
+			if since != "" {
+				if query.Since, err = time.ParseInLocation("2006-01-02", since, time.Local); err != nil {
+					return fmt.Errorf("--since: want a date like 2024-01-31")
+				}
+			}
+
+			var groups []group
+			for _, tag := range tags {
+				query.Tag = tag
+				records, err := history.Find(query)
+				if err != nil {
+					return err
+				}
+				g := group{tag: tag}
+				for _, record := range records {
+					if !record.Stats.Comparable() {
+						continue
+					}
+					g.wpm = append(g.wpm, record.Stats.WPM)
+					g.accuracy = append(g.accuracy, record.Stats.Accuracy)
+				}
+				groups = append(groups, g)
+			}
+
+			report(groups)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag to compare (give two or more)")
+	cmd.Flags().StringVar(&mode, "mode", "", "Only compare races of this mode")
+	cmd.Flags().StringVar(&since, "since", "", "Only compare races on or after this date (YYYY-MM-DD)")
+	return cmd
+}
+
+func report(groups []group) {
+	width := len("TAG")
+	for _, g := range groups {
+		width = max(width, len(g.tag))
+	}
+
+	for _, metric := range []struct {
+		name   string
+		unit   string
+		values func(group) []float64
+	}{
+		{"WPM", "", func(g group) []float64 { return g.wpm }},
+		{"Accuracy", " points", func(g group) []float64 { return g.accuracy }},
+	} {
+		fmt.Println(metric.name)
+		fmt.Printf("  %-*s %6s %8s %7s %8s %8s %8s\n", width, "TAG", "RACES", "MEAN", "SD", "MEDIAN", "MIN", "MAX")
+		var all []float64
+		for _, g := range groups {
+			d := Describe(metric.values(g))
+			all = append(all, metric.values(g)...)
+			if d.N == 0 {
+				fmt.Printf("  %-*s %6d\n", width, g.tag, 0)
+				continue
+			}
+			fmt.Printf("  %-*s %6d %8.2f %7.2f %8.2f %8.2f %8.2f\n",
+				width, g.tag, d.N, d.Mean, d.SD, d.Median, d.Min, d.Max)
+		}
+		if histogram := distributions(groups, metric.values, all, width); histogram != "" {
+			fmt.Print(histogram)
+		}
+
+		first := Describe(metric.values(groups[0]))
+		for _, g := range groups[1:] {
+			fmt.Printf("  %s vs %s: %s\n", g.tag, groups[0].tag, verdict(first, Describe(metric.values(g)), metric.unit))
+		}
+		fmt.Println()
+	}
+}
+
+// distributions draws a histogram per group over bins shared by all of
+// them, so their shapes line up.
+func distributions(groups []group, values func(group) []float64, all []float64, width int) string {
+	const columns = 20
+	whole := Describe(all)
+	if whole.N == 0 || whole.Max == whole.Min {
+		return ""
+	}
+
+	step := (whole.Max - whole.Min) / columns
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Distribution, %.1f to %.1f:\n", whole.Min, whole.Max)
+	for _, g := range groups {
+		if len(values(g)) == 0 {
+			continue
+		}
+		counts := Histogram(values(g), whole.Min, step, columns)
+		peak := 0
+		for _, count := range counts {
+			peak = max(peak, count)
+		}
+		fmt.Fprintf(&b, "  %-*s ", width, g.tag)
+		for _, count := range counts {
+			if count == 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			b.WriteRune(bars[(count*(len(bars)-1)+peak-1)/peak])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func verdict(a, b Distribution, unit string) string {
+	test, ok := Welch(a, b)
+	if !ok {
+		return "need at least 2 races with each tag to test"
+	}
+
+	change := fmt.Sprintf("%+.2f%s", test.Difference, unit)
+	if a.Mean != 0 {
+		change += fmt.Sprintf(" (%+.1f%%)", test.Difference/a.Mean*100)
+	}
+	p := fmt.Sprintf("p=%.3f", test.P)
+	if test.P < 0.001 {
+		p = "p<0.001"
+	}
+	stats := fmt.Sprintf("t=%.2f, df=%.1f, %s, d=%.2f", test.T, test.DF, p, test.D)
+	if math.IsInf(test.T, 0) {
+		stats = fmt.Sprintf("no variation, %s", p)
+	}
+
+	if test.P < significance {
+		return fmt.Sprintf("%s, %s: significant at the %.0f%% level", change, stats, significance*100)
+	}
+	return fmt.Sprintf("%s, %s: not significant, could be chance", change, stats)
+}
@@ -0,0 +1,155 @@
+package compare
+
+import (
+	"math"
+	"sort"
+)
+
+// Distribution summarises a set of measurements.
+type Distribution struct {
+	N      int
+	Mean   float64
+	SD     float64
+	Median float64
+	Min    float64
+	Max    float64
+}
+
+// Describe computes the distribution of values. SD is the sample standard
+// deviation, zero with fewer than two values.
+func Describe(values []float64) Distribution {
+	d := Distribution{N: len(values)}
+	if d.N == 0 {
+		return d
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	d.Min, d.Max = sorted[0], sorted[d.N-1]
+	if d.N%2 == 1 {
+		d.Median = sorted[d.N/2]
+	} else {
+		d.Median = (sorted[d.N/2-1] + sorted[d.N/2]) / 2
+	}
+
+	for _, v := range values {
+		d.Mean += v
+	}
+	d.Mean /= float64(d.N)
+	if d.N > 1 {
+		for _, v := range values {
+			d.SD += (v - d.Mean) * (v - d.Mean)
+		}
+		d.SD = math.Sqrt(d.SD / float64(d.N-1))
+	}
+	return d
+}
+
+// TTest is the result of Welch's t-test for a difference between the means
+// of two groups, which does not assume they have the same variance.
+type TTest struct {
+	// Difference is the second group's mean minus the first's.
+	Difference float64
+	T          float64
+	DF         float64
+	// P is the two-sided p-value: how likely a difference at least this
+	// large would be if the groups had the same mean.
+	P float64
+	// D is Cohen's d, the difference in units of the pooled standard
+	// deviation.
+	D float64
+}
+
+// Welch compares the means of a and b. Both need at least two values.
+func Welch(a, b Distribution) (TTest, bool) {
+	if a.N < 2 || b.N < 2 {
+		return TTest{}, false
+	}
+
+	test := TTest{Difference: b.Mean - a.Mean}
+	va, vb := a.SD*a.SD/float64(a.N), b.SD*b.SD/float64(b.N)
+	pooled := math.Sqrt((float64(a.N-1)*a.SD*a.SD + float64(b.N-1)*b.SD*b.SD) / float64(a.N+b.N-2))
+	if pooled > 0 {
+		test.D = test.Difference / pooled
+	}
+
+	// Without any spread the means either match exactly or differ for sure.
+	if va+vb == 0 {
+		test.DF = float64(a.N + b.N - 2)
+		test.P = 1
+		if test.Difference != 0 {
+			test.T, test.P = math.Copysign(math.Inf(1), test.Difference), 0
+		}
+		return test, true
+	}
+
+	test.T = test.Difference / math.Sqrt(va+vb)
+	test.DF = (va + vb) * (va + vb) / (va*va/float64(a.N-1) + vb*vb/float64(b.N-1))
+	test.P = betaInc(test.DF/2, 0.5, test.DF/(test.DF+test.T*test.T))
+	return test, true
+}
+
+// betaInc is the regularized incomplete beta function I_x(a, b), which
+// gives the tails of Student's t distribution.
+func betaInc(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+// betaFraction evaluates the continued fraction for betaInc by Lentz's
+// method.
+func betaFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= maxIterations; m++ {
+		even := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / clamp(1+even*d)
+		c = clamp(1 + even/c)
+		h *= d * c
+
+		odd := -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / clamp(1+odd*d)
+		c = clamp(1 + odd/c)
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
+
+// Histogram counts values in bins of equal width from lo to hi.
+func Histogram(values []float64, lo, width float64, bins int) []int {
+	counts := make([]int, bins)
+	for _, v := range values {
+		i := int((v - lo) / width)
+		counts[max(0, min(bins-1, i))]++
+	}
+	return counts
+}
@@ -0,0 +1,140 @@
+package compare
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Distribution
+	}{
+		{"empty", nil, Distribution{}},
+		{"one", []float64{5}, Distribution{N: 1, Mean: 5, Median: 5, Min: 5, Max: 5}},
+		{"odd", []float64{3, 1, 2}, Distribution{N: 3, Mean: 2, SD: 1, Median: 2, Min: 1, Max: 3}},
+		{"even", []float64{4, 1, 3, 2}, Distribution{N: 4, Mean: 2.5, SD: math.Sqrt(5.0 / 3), Median: 2.5, Min: 1, Max: 4}},
+	}
+	for _, tt := range tests {
+		got := Describe(tt.values)
+		if got.N != tt.want.N || !near(got.Mean, tt.want.Mean, 1e-12) || !near(got.SD, tt.want.SD, 1e-12) ||
+			got.Median != tt.want.Median || got.Min != tt.want.Min || got.Max != tt.want.Max {
+			t.Errorf("%s: Describe = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBetaInc(t *testing.T) {
+	tests := []struct {
+		a, b, x float64
+		want    float64
+	}{
+		{1, 1, 0.3, 0.3},
+		{3, 1, 0.5, 0.125},
+		{1, 4, 0.5, 1 - math.Pow(0.5, 4)},
+		{7.5, 7.5, 0.5, 0.5},
+		{2, 3, 0, 0},
+		{2, 3, 1, 1},
+		// I_x(2, 2) = 3x² - 2x³.
+		{2, 2, 0.9, 3*0.81 - 2*0.729},
+	}
+	for _, tt := range tests {
+		if got := betaInc(tt.a, tt.b, tt.x); !near(got, tt.want, 1e-12) {
+			t.Errorf("betaInc(%v, %v, %v) = %v, want %v", tt.a, tt.b, tt.x, got, tt.want)
+		}
+	}
+}
+
+// tTail is the two-sided p-value of Student's t distribution with df
+// degrees of freedom, as Welch works it out.
+func tTail(t, df float64) float64 {
+	return betaInc(df/2, 0.5, df/(df+t*t))
+}
+
+func TestStudentTail(t *testing.T) {
+	tests := []struct {
+		t, df float64
+		want  float64
+	}{
+		// One degree of freedom is the Cauchy distribution.
+		{1, 1, 0.5},
+		{3, 1, 1 - 2/math.Pi*math.Atan(3)},
+		// Two have a closed form too.
+		{2, 2, 1 - 2/math.Sqrt(6)},
+		// Critical values from t tables.
+		{2.228138851986274, 10, 0.05},
+		{1.959963984540054, 1e6, 0.05},
+		{2.576, 1e6, 0.01},
+		{0, 5, 1},
+	}
+	for _, tt := range tests {
+		if got := tTail(tt.t, tt.df); !near(got, tt.want, 1e-4) {
+			t.Errorf("p(t=%v, df=%v) = %v, want %v", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestWelch(t *testing.T) {
+	// The first example of the Wikipedia article on Welch's t-test.
+	a := Describe([]float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4})
+	b := Describe([]float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4})
+
+	test, ok := Welch(a, b)
+	if !ok {
+		t.Fatal("Welch refused two groups of 15")
+	}
+	if !near(test.Difference, 2.1667, 1e-4) || !near(test.T, 2.4554, 1e-4) ||
+		!near(test.DF, 24.9885, 1e-4) || !near(test.P, 0.02138, 1e-4) {
+		t.Errorf("Welch = %+v, want difference 2.1667, t 2.4554, df 24.9885, p 0.02138", test)
+	}
+	if !near(test.D, 0.8966, 1e-3) {
+		t.Errorf("Cohen's d = %v, want 0.8966", test.D)
+	}
+
+	reverse, _ := Welch(b, a)
+	if !near(reverse.T, -test.T, 1e-12) || !near(reverse.P, test.P, 1e-12) {
+		t.Errorf("swapping the groups gives %+v, want t negated and the same p", reverse)
+	}
+}
+
+func TestWelchEdgeCases(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		ok   bool
+		p    float64
+		inf  bool
+	}{
+		{"too few", []float64{1}, []float64{1, 2}, false, 0, false},
+		{"identical constants", []float64{5, 5}, []float64{5, 5, 5}, true, 1, false},
+		{"different constants", []float64{5, 5}, []float64{6, 6}, true, 0, true},
+		{"same mean", []float64{1, 3}, []float64{2, 2, 2}, true, 1, false},
+	}
+	for _, tt := range tests {
+		test, ok := Welch(Describe(tt.a), Describe(tt.b))
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !near(test.P, tt.p, 1e-12) || math.IsInf(test.T, 0) != tt.inf {
+			t.Errorf("%s: Welch = %+v, want p %v", tt.name, test, tt.p)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	got := Histogram([]float64{0, 0.5, 1, 2.9, 3, 10, -1}, 0, 1, 3)
+	// Values below and above the range land in the first and last bins.
+	if want := []int{3, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Histogram = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"fmt"
+
+	"go-typ0/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the history database",
+		Long: `Races, their keystrokes and per-key totals are kept in an embedded
+database in the profile's data directory. It is migrated to the latest
+schema automatically; these commands are for maintenance.`,
+	}
+
+	cmd.AddCommand(newMigrateCommand(), newCheckCommand(), newVacuumCommand())
+	return cmd
+}
+
+func newMigrateCommand() *cobra.Command {
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Bring the database schema up to date",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				for _, m := range history.Migrations() {
+					fmt.Printf("%3d  %s\n", m.Version, m.Description)
+				}
+				return nil
+			}
+
+			status, applied, err := history.Migrate()
+			for _, m := range applied {
+				fmt.Printf("Applied %d: %s\n", m.Version, m.Description)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Printf("Already at schema version %d\n", status.Schema)
+			}
+			printStatus(status)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List the migrations instead of applying them")
+	return cmd
+}
+
+func newCheckCommand() *cobra.Command {
+	var repair bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Verify the database and its indices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, problems, err := history.Check(repair)
+			if err != nil {
+				return err
+			}
+			printStatus(status)
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+				return nil
+			}
+
+			for _, problem := range problems {
+				fmt.Printf("- %s\n", problem)
+			}
+			if repair {
+				fmt.Println("Rebuilt the indices and key totals. Run the check again to confirm.")
+				return nil
+			}
+			return fmt.Errorf("%d problems found; run with --repair to rebuild the indices and key totals", len(problems))
+		},
+	}
+
+	cmd.Flags().BoolVar(&repair, "repair", false, "Rebuild the indices and key totals if there are problems")
+	return cmd
+}
+
+func newVacuumCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "vacuum",
+		Short: "Reclaim unused space in the database file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			before, after, err := history.Vacuum()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Database: %s -> %s\n", size(before), size(after))
+			return nil
+		},
+	}
+}
+
+func printStatus(status history.Status) {
+	fmt.Printf("Database: %s\n", status.Path)
+	fmt.Printf("Schema:   %d (latest %d)\n", status.Schema, history.LatestSchema())
+	fmt.Printf("Races:    %d\n", status.Races)
+	fmt.Printf("Size:     %s\n", size(status.Size))
+}
+
+func size(bytes int64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", bytes)
+}
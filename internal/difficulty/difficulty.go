@@ -0,0 +1,254 @@
+package difficulty
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/layout"
+)
+
+// Score breaks down how hard a text is to type. The parts are averages
+// over the text, so long and short texts compare fairly.
+type Score struct {
+	// Rarity is how uncommon the letters are, from 0 when every letter is
+	// the most common one to 1 when every letter is the rarest.
+	Rarity float64
+	// Shifted is the share of characters typed with Shift.
+	Shifted float64
+	// Symbols is the share of digits and punctuation.
+	Symbols float64
+	// WordLength is the average number of characters per word.
+	WordLength float64
+	// SameFinger is the share of key pairs typed one after the other with
+	// the same finger.
+	SameFinger float64
+	// Awkwardness measures reaches away from the home row and jumps over
+	// it within one hand, on the layout typed.
+	Awkwardness float64
+	// Total combines the parts, from 0 for the easiest texts; plain
+	// lowercase prose scores about 3 to 4.
+	Total float64
+}
+
+// Weights of the parts in Total.
+const (
+	rarityWeight     = 2.5
+	shiftedWeight    = 10
+	symbolsWeight    = 10
+	wordLengthWeight = 0.4
+	sameFingerWeight = 10
+	awkwardWeight    = 4
+	// shortWord is the length up to which words add nothing to Total.
+	shortWord = 3
+)
+
+// Reach costs of the number, top, home and bottom rows.
+var rowCost = [layout.Rows]float64{1, 0.4, 0, 0.5}
+
+// englishFrequency is the share of each letter in English text, used when
+// no sample of the language is given.
+var englishFrequency = map[rune]float64{
+	'e': 12.7, 't': 9.06, 'a': 8.17, 'o': 7.51, 'i': 6.97, 'n': 6.75, 's': 6.33,
+	'h': 6.09, 'r': 5.99, 'd': 4.25, 'l': 4.03, 'c': 2.78, 'u': 2.76, 'm': 2.41,
+	'w': 2.36, 'f': 2.23, 'g': 2.02, 'y': 1.97, 'p': 1.93, 'b': 1.29, 'v': 0.98,
+	'k': 0.77, 'j': 0.15, 'x': 0.15, 'q': 0.095, 'z': 0.074,
+}
+
+// Scorer rates texts for one layout, finger map and language.
+type Scorer struct {
+	layout  *layout.Layout
+	fingers *keyboard.FingerMap
+	rarity  map[rune]float64
+}
+
+// NewScorer rates texts typed on lay with fingers, judging letter rarity by
+// the letters of sample, such as a language pack's word list. Nil
+// arguments fall back to QWERTY, standard fingering and English.
+func NewScorer(lay *layout.Layout, fingers *keyboard.FingerMap, sample []string) *Scorer {
+	if lay == nil {
+		lay = layout.QWERTY()
+	}
+	if fingers == nil {
+		fingers = keyboard.StandardFingers()
+	}
+
+	frequency := englishFrequency
+	if len(sample) > 0 {
+		frequency = make(map[rune]float64)
+		for _, word := range sample {
+			for _, r := range word {
+				if unicode.IsLetter(r) {
+					frequency[unicode.ToLower(r)]++
+				}
+			}
+		}
+	}
+	return &Scorer{layout: lay, fingers: fingers, rarity: rarities(frequency)}
+}
+
+// rarities maps each letter's frequency onto a log scale from 0 for the
+// most common letter to 1 for the rarest.
+func rarities(frequency map[rune]float64) map[rune]float64 {
+	most, least := 0.0, math.Inf(1)
+	for _, f := range frequency {
+		most, least = math.Max(most, f), math.Min(least, f)
+	}
+	rarity := make(map[rune]float64, len(frequency))
+	for r, f := range frequency {
+		if most > least {
+			rarity[r] = math.Log(most/f) / math.Log(most/least)
+		}
+	}
+	return rarity
+}
+
+var standard = NewScorer(nil, nil, nil)
+
+// Rate scores text as typed in English on QWERTY with standard fingering.
+func Rate(text string) Score {
+	return standard.Score(text)
+}
+
+func (s *Scorer) Score(text string) Score {
+	var (
+		score                        Score
+		chars, letters, pairs, words int
+		previous                     keyboard.Press
+		previousOK                   bool
+		previousRow                  int
+	)
+	for _, word := range strings.Fields(text) {
+		words++
+		previousOK = false
+		for _, r := range word {
+			chars++
+			if unicode.IsLetter(r) {
+				letters++
+				rarity, ok := s.rarity[unicode.ToLower(r)]
+				if !ok {
+					rarity = 1
+				}
+				score.Rarity += rarity
+			} else {
+				score.Symbols++
+			}
+
+			press, ok := keyboard.Locate(r, s.layout, s.fingers)
+			row, _, shift, _ := s.layout.Position(r)
+			if !ok {
+				// Characters the layout lacks need a compose key or the like.
+				score.Awkwardness++
+				if unicode.IsUpper(r) {
+					score.Shifted++
+				}
+				previousOK = false
+				continue
+			}
+			if shift {
+				score.Shifted++
+			}
+			score.Awkwardness += rowCost[row]
+
+			if previousOK {
+				pairs++
+				if press.Finger == previous.Finger && press.Key != previous.Key {
+					score.SameFinger++
+				}
+				if press.Finger.Hand() == previous.Finger.Hand() && abs(row-previousRow) >= 2 {
+					score.Awkwardness++
+				}
+			}
+			previous, previousOK, previousRow = press, true, row
+		}
+	}
+	if chars == 0 {
+		return Score{}
+	}
+
+	if letters > 0 {
+		score.Rarity /= float64(letters)
+	}
+	score.Shifted /= float64(chars)
+	score.Symbols /= float64(chars)
+	score.Awkwardness /= float64(chars)
+	if pairs > 0 {
+		score.SameFinger /= float64(pairs)
+	}
+	score.WordLength = float64(chars) / float64(words)
+
+	score.Total = rarityWeight*score.Rarity +
+		shiftedWeight*score.Shifted +
+		symbolsWeight*score.Symbols +
+		wordLengthWeight*math.Max(0, score.WordLength-shortWord) +
+		sameFingerWeight*score.SameFinger +
+		awkwardWeight*score.Awkwardness
+	return score
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Level is a band of difficulty scores.
+type Level int
+
+const (
+	Any Level = iota
+	Easy
+	Medium
+	Hard
+)
+
+// Upper bounds of the easy and medium bands.
+const (
+	easyBelow   = 3.5
+	mediumBelow = 5
+)
+
+var levelNames = []string{"any", "easy", "medium", "hard"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return "unknown"
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return Any, fmt.Errorf("unknown difficulty %q (use easy, medium or hard)", s)
+}
+
+// LevelOf returns the band total falls in.
+func LevelOf(total float64) Level {
+	switch {
+	case total < easyBelow:
+		return Easy
+	case total < mediumBelow:
+		return Medium
+	}
+	return Hard
+}
+
+// Distance is how far total is outside the band, 0 when it is inside.
+func (l Level) Distance(total float64) float64 {
+	switch l {
+	case Easy:
+		return math.Max(0, total-easyBelow)
+	case Medium:
+		return math.Max(0, math.Max(easyBelow-total, total-mediumBelow))
+	case Hard:
+		return math.Max(0, mediumBelow-total)
+	}
+	return 0
+}
@@ -0,0 +1,135 @@
+package difficulty
+
+import (
+	"math"
+	"testing"
+
+	"go-typ0/internal/layout"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		part func(Score) float64
+		want float64
+	}{
+		{"common letter", "eee", func(s Score) float64 { return s.Rarity }, 0},
+		{"rarest letter", "zzz", func(s Score) float64 { return s.Rarity }, 1},
+		{"letter outside the language", "ééé", func(s Score) float64 { return s.Rarity }, 1},
+		{"lowercase", "asdf", func(s Score) float64 { return s.Shifted }, 0},
+		{"capitals", "Asdf", func(s Score) float64 { return s.Shifted }, 0.25},
+		{"shifted symbol", "a!", func(s Score) float64 { return s.Shifted }, 0.5},
+		{"letters only", "asdf", func(s Score) float64 { return s.Symbols }, 0},
+		{"digits and punctuation", "a1.b", func(s Score) float64 { return s.Symbols }, 0.5},
+		{"word length", "ab cdef", func(s Score) float64 { return s.WordLength }, 3},
+		{"same finger", "ed", func(s Score) float64 { return s.SameFinger }, 1},
+		{"same key twice", "ee", func(s Score) float64 { return s.SameFinger }, 0},
+		{"alternating hands", "fj", func(s Score) float64 { return s.SameFinger }, 0},
+		{"pairs stop at spaces", "e d", func(s Score) float64 { return s.SameFinger }, 0},
+		{"home row", "asdf", func(s Score) float64 { return s.Awkwardness }, 0},
+		{"top row", "qwer", func(s Score) float64 { return s.Awkwardness }, 0.4},
+		{"jump over the home row", "ec", func(s Score) float64 { return s.Awkwardness }, (0.4 + 0.5 + 1) / 2},
+		{"key the layout lacks", "ß", func(s Score) float64 { return s.Awkwardness }, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.part(Rate(tt.text)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Rate(%q) part = %v, want %v", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestScoreTotal(t *testing.T) {
+	if got := Rate(""); got != (Score{}) {
+		t.Errorf("Rate of an empty text = %+v, want zero", got)
+	}
+	if got := Rate("   "); got != (Score{}) {
+		t.Errorf("Rate of blank text = %+v, want zero", got)
+	}
+
+	prose := Rate("the quick brown fox jumps over the lazy dog").Total
+	if prose < 2 || prose > 5 {
+		t.Errorf("plain prose scores %v, want about 3 to 4", prose)
+	}
+	if hard := Rate("Qu'est-ce? #42 {x_z} & 99%!").Total; hard <= prose {
+		t.Errorf("symbols and capitals score %v, no more than prose at %v", hard, prose)
+	}
+}
+
+func TestScorerLayout(t *testing.T) {
+	colemak, err := layout.Load("colemak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// arst is the home row on Colemak but reaches up twice on QWERTY.
+	if got := NewScorer(colemak, nil, nil).Score("arst").Awkwardness; got != 0 {
+		t.Errorf("arst on Colemak: awkwardness %v, want 0", got)
+	}
+	if got := Rate("arst").Awkwardness; math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("arst on QWERTY: awkwardness %v, want 0.2", got)
+	}
+}
+
+func TestScorerSample(t *testing.T) {
+	scorer := NewScorer(nil, nil, []string{"zzz", "Zz", "e"})
+	if got := scorer.Score("z").Rarity; got != 0 {
+		t.Errorf("z common in the sample: rarity %v, want 0", got)
+	}
+	if got := scorer.Score("e").Rarity; got != 1 {
+		t.Errorf("e rare in the sample: rarity %v, want 1", got)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		total float64
+		level Level
+	}{
+		{0, Easy},
+		{3.49, Easy},
+		{3.5, Medium},
+		{4.99, Medium},
+		{5, Hard},
+		{12, Hard},
+	}
+	for _, tt := range tests {
+		if got := LevelOf(tt.total); got != tt.level {
+			t.Errorf("LevelOf(%v) = %v, want %v", tt.total, got, tt.level)
+		}
+		if d := tt.level.Distance(tt.total); d != 0 {
+			t.Errorf("%v.Distance(%v) = %v, want 0 inside the band", tt.level, tt.total, d)
+		}
+	}
+
+	distances := []struct {
+		level Level
+		total float64
+		want  float64
+	}{
+		{Easy, 4, 0.5},
+		{Medium, 3, 0.5},
+		{Medium, 6, 1},
+		{Hard, 4, 1},
+		{Any, 100, 0},
+	}
+	for _, tt := range distances {
+		if got := tt.level.Distance(tt.total); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%v.Distance(%v) = %v, want %v", tt.level, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for i, name := range []string{"any", "easy", "Medium", "HARD"} {
+		level, err := ParseLevel(name)
+		if err != nil || level != Level(i) {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", name, level, err, Level(i))
+		}
+	}
+	if _, err := ParseLevel("insane"); err == nil {
+		t.Error("ParseLevel accepted an unknown level")
+	}
+	if got := Level(9).String(); got != "unknown" {
+		t.Errorf("Level(9) = %q, want unknown", got)
+	}
+}
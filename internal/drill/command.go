@@ -0,0 +1,122 @@
+package drill
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+	"go-typ0/internal/words"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	var (
+		keys       string
+		bigrams    []string
+		wordCount  int
+		only       bool
+		confusions int
+		problems   int
+		lang       string
+		langDir    string
+		flags      race.Flags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "drill",
+		Short: "Practise specific keys or bigrams",
+		Long: `Generate practice text that concentrates on the given keys and bigrams.
+Real words from the language pack are used where possible, topped up with
+pronounceable nonsense words when too few of them match.`,
+		Example: `  typ0 drill --keys qzx
+  typ0 drill --bigrams th,ing --words 30
+  typ0 drill --keys asdfjkl --only
+  typ0 drill --confusions 3
+  typ0 drill --problem-words 10`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if confusions > 0 {
+				confused, err := confusedKeys(confusions)
+				if err != nil {
+					return err
+				}
+				keys += confused
+			}
+
+			var problemWords []string
+			if problems > 0 {
+				var err error
+				if problemWords, err = history.ProblemWords(problems); err != nil {
+					return err
+				}
+			}
+
+			target := NewTarget(keys, bigrams)
+			if target.Empty() && len(problemWords) == 0 {
+				return errors.New("nothing to drill: pass --keys, --bigrams, --confusions or --problem-words (the last two need some races in the history)")
+			}
+
+			pack, err := words.Resolve(lang, langDir)
+			if err != nil {
+				return err
+			}
+
+			opts := race.Options{
+				Pack: pack,
+				Source: func() string {
+					if target.Empty() {
+						return Repeat(problemWords, wordCount)
+					}
+					return Generate(append(problemWords, pack.Words...), target, wordCount, only)
+				},
+			}
+			if err := flags.Apply(&opts); err != nil {
+				return err
+			}
+
+			model := race.NewModel(opts)
+			viewModel := race.NewViewModel(model)
+			saveErr := race.Record(viewModel, "drill", opts)
+
+			p := tea.NewProgram(viewModel)
+			if _, err := p.Run(); err != nil {
+				fmt.Println("Error running program: ", err)
+				os.Exit(1)
+			}
+			return saveErr()
+		},
+	}
+
+	cmd.Flags().StringVarP(&keys, "keys", "k", "", "Keys to practise, e.g. \"qzx\"")
+	cmd.Flags().StringSliceVarP(&bigrams, "bigrams", "b", nil, "Comma separated bigrams to practise, e.g. \"th,ing\"")
+	cmd.Flags().IntVarP(&wordCount, "words", "w", 20, "Number of words in the drill")
+	cmd.Flags().BoolVar(&only, "only", false, "Use only words made entirely of the drilled keys")
+	cmd.Flags().IntVar(&confusions, "confusions", 0, "Also drill both keys of your N most common confusions from the history")
+	cmd.Flags().IntVar(&problems, "problem-words", 0, "Drill your N worst words from the history, or mix them in when drilling keys")
+	cmd.Flags().StringVarP(&lang, "lang", "l", "", "Language pack to draw words from (defaults to $LANG)")
+	cmd.Flags().StringVar(&langDir, "lang-dir", "", "Extra directory to search for language packs")
+	flags.Register(cmd)
+
+	return cmd
+}
+
+// confusedKeys returns the keys of the n most common confusions in the
+// history, expected and typed alike, so the drill makes you tell them apart.
+func confusedKeys(n int) (string, error) {
+	records, err := history.Load()
+	if err != nil {
+		return "", err
+	}
+
+	var keys []rune
+	for i, c := range race.MergeConfusions(history.AllStats(records)) {
+		if i >= n {
+			break
+		}
+		keys = append(keys, c.Expected, c.Typed)
+	}
+	return string(keys), nil
+}
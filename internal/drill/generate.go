@@ -0,0 +1,231 @@
+package drill
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minPool is the number of distinct real words a drill needs before it
+// stops padding the text with generated nonsense words.
+const minPool = 15
+
+var (
+	vowels     = []rune("aeiou")
+	consonants = []rune("tnshrdlcmpbgfkvwy")
+)
+
+type Target struct {
+	Keys    []rune
+	Bigrams []string
+}
+
+func NewTarget(keys string, bigrams []string) Target {
+	var target Target
+	seen := make(map[rune]bool)
+	for _, r := range strings.ToLower(keys) {
+		if unicode.IsSpace(r) || r == ',' || seen[r] {
+			continue
+		}
+		seen[r] = true
+		target.Keys = append(target.Keys, r)
+	}
+	for _, bigram := range bigrams {
+		bigram = strings.ToLower(strings.TrimSpace(bigram))
+		if bigram != "" {
+			target.Bigrams = append(target.Bigrams, bigram)
+		}
+	}
+	return target
+}
+
+func (t Target) Empty() bool {
+	return len(t.Keys) == 0 && len(t.Bigrams) == 0
+}
+
+type scored struct {
+	word  string
+	score float64
+}
+
+// Generate returns count words drawn from list that exercise the target
+// keys and bigrams. With only set, words must consist solely of the target
+// keys. When fewer than minPool real words qualify, pronounceable nonsense
+// built around the targets makes up the difference.
+func Generate(list []string, target Target, count int, only bool) string {
+	if count <= 0 {
+		count = 20
+	}
+
+	pool := candidates(list, target, only)
+
+	var result []string
+	for i := 0; i < count; i++ {
+		if len(pool) == 0 || (len(pool) < minPool && rand.Intn(minPool) >= len(pool)) {
+			result = append(result, Nonsense(target, only))
+			continue
+		}
+		result = append(result, pick(pool))
+	}
+	return strings.Join(result, " ")
+}
+
+// Repeat returns count words picked at random from list.
+func Repeat(list []string, count int) string {
+	if count <= 0 {
+		count = 20
+	}
+	result := make([]string, count)
+	for i := range result {
+		result[i] = list[rand.Intn(len(list))]
+	}
+	return strings.Join(result, " ")
+}
+
+func candidates(list []string, target Target, only bool) []scored {
+	allowed := make(map[rune]bool)
+	for _, r := range target.Keys {
+		allowed[r] = true
+	}
+	for _, bigram := range target.Bigrams {
+		for _, r := range bigram {
+			allowed[r] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var pool []scored
+	for _, word := range list {
+		lower := strings.ToLower(word)
+		if seen[lower] || len([]rune(lower)) < 2 {
+			continue
+		}
+		seen[lower] = true
+
+		if only && !onlyUses(lower, allowed) {
+			continue
+		}
+		if score := score(lower, target); score > 0 {
+			pool = append(pool, scored{word: lower, score: score})
+		}
+	}
+
+	// Keep the better half so "mostly" still means mostly, but never cut
+	// the pool below what is needed to avoid nonsense words.
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].score > pool[j].score })
+	if keep := len(pool) / 2; keep >= minPool {
+		pool = pool[:keep]
+	}
+	return pool
+}
+
+func onlyUses(word string, allowed map[rune]bool) bool {
+	for _, r := range word {
+		if !allowed[r] {
+			return false
+		}
+	}
+	return true
+}
+
+// score is the share of the word's characters covered by target keys and
+// bigrams.
+func score(word string, target Target) float64 {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	hits := 0
+	for _, r := range runes {
+		for _, key := range target.Keys {
+			if r == key {
+				hits++
+				break
+			}
+		}
+	}
+	for _, bigram := range target.Bigrams {
+		hits += strings.Count(word, bigram) * len([]rune(bigram))
+	}
+	return float64(hits) / float64(len(runes))
+}
+
+func pick(pool []scored) string {
+	total := 0.0
+	for _, s := range pool {
+		total += s.score
+	}
+	n := rand.Float64() * total
+	for _, s := range pool {
+		n -= s.score
+		if n <= 0 {
+			return s.word
+		}
+	}
+	return pool[len(pool)-1].word
+}
+
+// Nonsense builds a pronounceable pseudo-word out of two or three
+// syllables, each containing one of the target keys or bigrams. With only
+// set, filler letters are also taken from the targets.
+func Nonsense(target Target, only bool) string {
+	pieces := make([]string, 0, len(target.Keys)+len(target.Bigrams))
+	for _, key := range target.Keys {
+		pieces = append(pieces, string(key))
+	}
+	pieces = append(pieces, target.Bigrams...)
+	if len(pieces) == 0 {
+		pieces = []string{string(vowels[rand.Intn(len(vowels))])}
+	}
+
+	fillVowels, fillConsonants := vowels, consonants
+	if only {
+		fillVowels, fillConsonants = split(target)
+	}
+
+	var b strings.Builder
+	syllables := 2 + rand.Intn(2)
+	for i := 0; i < syllables; i++ {
+		piece := pieces[rand.Intn(len(pieces))]
+		last := []rune(piece)[len([]rune(piece))-1]
+
+		// Pair the piece with a filler of the opposite kind so the result
+		// alternates consonants and vowels and stays pronounceable.
+		filler := fillVowels
+		if isVowel(last) {
+			filler = fillConsonants
+		}
+		if len(filler) == 0 {
+			b.WriteString(piece)
+			continue
+		}
+		f := string(filler[rand.Intn(len(filler))])
+		if rand.Intn(2) == 0 {
+			b.WriteString(piece + f)
+		} else {
+			b.WriteString(f + piece)
+		}
+	}
+	return b.String()
+}
+
+func split(target Target) (v, c []rune) {
+	for _, r := range target.Keys {
+		if isVowel(r) {
+			v = append(v, r)
+		} else if unicode.IsLetter(r) {
+			c = append(c, r)
+		}
+	}
+	return v, c
+}
+
+func isVowel(r rune) bool {
+	switch unicode.ToLower(r) {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'ä', 'ö', 'ü', 'á', 'é', 'í', 'ó', 'ú', 'à', 'è', 'ê', 'â', 'ô', 'ã', 'õ', 'ą', 'ę':
+		return true
+	}
+	return false
+}
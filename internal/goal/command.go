@@ -0,0 +1,212 @@
+package goal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goal",
+		Short: "Set practice goals and follow your streak",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			summary, err := Summary()
+			if err != nil {
+				return err
+			}
+			if summary == "" {
+				fmt.Println("No goals yet. Set one with: typ0 goal set --minutes 15")
+				return nil
+			}
+			fmt.Println(summary)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newSetCommand(), newClearCommand())
+	return cmd
+}
+
+func newSetCommand() *cobra.Command {
+	var (
+		minutes int
+		races   int
+		wpm     float64
+		by      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set daily or speed goals",
+		Long: `Set how much to practise every day and a speed to reach. Goals not given
+keep their current value; remove one with "typ0 goal clear".`,
+		Example: `  typ0 goal set --minutes 15
+  typ0 goal set --races 10
+  typ0 goal set --wpm 80 --by 2026-12-31`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			goals, err := Load()
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			if flags.NFlag() == 0 {
+				return errors.New("nothing to set: pass --minutes, --races, --wpm or --by")
+			}
+			var update Update
+			if flags.Changed("minutes") {
+				update.DailyMinutes = &minutes
+			}
+			if flags.Changed("races") {
+				update.DailyRaces = &races
+			}
+			if flags.Changed("wpm") {
+				update.TargetWPM = &wpm
+			}
+			if flags.Changed("by") {
+				update.TargetBy = &by
+			}
+			if err := goals.Set(update); err != nil {
+				return err
+			}
+			if err := goals.Save(); err != nil {
+				return err
+			}
+			summary, err := Summary()
+			if err != nil {
+				return err
+			}
+			fmt.Println(summary)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&minutes, "minutes", 0, "Minutes to practise every day")
+	cmd.Flags().IntVar(&races, "races", 0, "Races to finish every day")
+	cmd.Flags().Float64Var(&wpm, "wpm", 0, "Speed to reach, as the average of your last 10 races")
+	cmd.Flags().StringVar(&by, "by", "", "Date to reach --wpm by (YYYY-MM-DD)")
+	return cmd
+}
+
+func newClearCommand() *cobra.Command {
+	var minutes, races, wpm, by bool
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove goals",
+		Long:  `Remove the goals named by the flags, or every goal when none are given.`,
+		Example: `  typ0 goal clear
+  typ0 goal clear --wpm --by`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().NFlag() == 0 {
+				return (&Goals{}).Save()
+			}
+			goals, err := Load()
+			if err != nil {
+				return err
+			}
+			if minutes {
+				goals.DailyMinutes = 0
+			}
+			if races {
+				goals.DailyRaces = 0
+			}
+			if wpm {
+				goals.TargetWPM = 0
+			}
+			if by {
+				goals.TargetBy = ""
+			}
+			return goals.Save()
+		},
+	}
+
+	cmd.Flags().BoolVar(&minutes, "minutes", false, "Remove the daily minutes goal")
+	cmd.Flags().BoolVar(&races, "races", false, "Remove the daily races goal")
+	cmd.Flags().BoolVar(&wpm, "wpm", false, "Remove the speed goal")
+	cmd.Flags().BoolVar(&by, "by", false, "Remove the date of the speed goal")
+	return cmd
+}
+
+// Summary describes today's progress, the streak and the speed goal, or
+// returns "" when there are neither goals nor races.
+func Summary() (string, error) {
+	goals, err := Load()
+	if err != nil {
+		return "", err
+	}
+	records, err := history.Load()
+	if err != nil {
+		return "", err
+	}
+	if goals.Empty() && len(records) == 0 {
+		return "", nil
+	}
+
+	now := time.Now()
+	progress := goals.Check(records, now)
+	styles := ui.NewStyles()
+
+	var lines []string
+	var today []string
+	if goals.DailyMinutes > 0 {
+		done := progress.Today.Duration.Minutes()
+		today = append(today, fmt.Sprintf("%s %.0f/%d min", bar(done/float64(goals.DailyMinutes)), done, goals.DailyMinutes))
+	}
+	if goals.DailyRaces > 0 {
+		today = append(today, fmt.Sprintf("%s %d/%d races", bar(float64(progress.Today.Races)/float64(goals.DailyRaces)), progress.Today.Races, goals.DailyRaces))
+	}
+	if len(today) == 0 {
+		today = append(today, fmt.Sprintf("%d %s, %.0f min", progress.Today.Races, plural(progress.Today.Races, "race"), progress.Today.Duration.Minutes()))
+	}
+	lines = append(lines, styles.LabelStyle.Render("Today: ")+styles.ValueStyle.Render(strings.Join(today, "  ")))
+
+	streak := fmt.Sprintf("%d %s", progress.Streak, plural(progress.Streak, "day"))
+	if progress.Longest > progress.Streak {
+		streak += fmt.Sprintf(" (best %d)", progress.Longest)
+	}
+	lines = append(lines, styles.LabelStyle.Render("Streak: ")+styles.ValueStyle.Render(streak))
+
+	if goals.TargetWPM > 0 {
+		target := fmt.Sprintf("%.0f WPM", goals.TargetWPM)
+		if deadline, ok := goals.Deadline(); ok {
+			left := int(deadline.Sub(now).Hours() / 24)
+			if left >= 0 {
+				target += fmt.Sprintf(" by %s (%d %s left)", goals.TargetBy, left, plural(left, "day"))
+			} else {
+				target += fmt.Sprintf(" by %s (passed)", goals.TargetBy)
+			}
+		}
+		status := fmt.Sprintf("now %.1f", progress.Recent)
+		if len(records) == 0 {
+			status = "no races yet"
+		} else if progress.Recent >= goals.TargetWPM {
+			status = styles.GreenStyle.Render(status + " ✓")
+		}
+		lines = append(lines, styles.LabelStyle.Render("Goal: ")+styles.ValueStyle.Render(target)+"  "+status)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func bar(fraction float64) string {
+	const width = 10
+	filled := int(min(fraction, 1) * width)
+	return strings.Repeat("▓", filled) + strings.Repeat("░", width-filled)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,194 @@
+package goal
+
+import (
+	"fmt"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/storage"
+)
+
+const (
+	goalsFile  = "goals.json"
+	dateFormat = "2006-01-02"
+	// recentRaces is how many of the latest races stand for the current
+	// speed when checking a WPM target.
+	recentRaces = 10
+)
+
+// Goals are what the user aims for. Zero values are unset. A day counts
+// towards the streak once every daily goal is met, or once any race is
+// finished when there are none.
+type Goals struct {
+	DailyMinutes int     `json:"daily_minutes,omitempty"`
+	DailyRaces   int     `json:"daily_races,omitempty"`
+	TargetWPM    float64 `json:"target_wpm,omitempty"`
+	TargetBy     string  `json:"target_by,omitempty"`
+}
+
+func Load() (*Goals, error) {
+	var goals Goals
+	if err := storage.Load(goalsFile, &goals); err != nil {
+		return nil, err
+	}
+	if err := goals.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", goalsFile, err)
+	}
+	return &goals, nil
+}
+
+// validate checks the goals that are set.
+func (g *Goals) validate() error {
+	switch {
+	case g.DailyMinutes < 0:
+		return fmt.Errorf("daily minutes must be above 0, got %d", g.DailyMinutes)
+	case g.DailyRaces < 0:
+		return fmt.Errorf("daily races must be above 0, got %d", g.DailyRaces)
+	case g.TargetWPM < 0:
+		return fmt.Errorf("target WPM must be above 0, got %g", g.TargetWPM)
+	}
+	if g.TargetBy != "" {
+		if _, err := time.Parse(dateFormat, g.TargetBy); err != nil {
+			return fmt.Errorf("target date must be like 2026-12-31, got %q", g.TargetBy)
+		}
+	}
+	return nil
+}
+
+// Update names the goals to change; nil fields keep their value.
+type Update struct {
+	DailyMinutes *int
+	DailyRaces   *int
+	TargetWPM    *float64
+	TargetBy     *string
+}
+
+// Set changes the goals given in update. Every target must be above zero;
+// "typ0 goal clear" removes goals instead.
+func (g *Goals) Set(update Update) error {
+	next := *g
+	if update.DailyMinutes != nil {
+		if next.DailyMinutes = *update.DailyMinutes; next.DailyMinutes <= 0 {
+			return fmt.Errorf("daily minutes must be above 0, got %d", next.DailyMinutes)
+		}
+	}
+	if update.DailyRaces != nil {
+		if next.DailyRaces = *update.DailyRaces; next.DailyRaces <= 0 {
+			return fmt.Errorf("daily races must be above 0, got %d", next.DailyRaces)
+		}
+	}
+	if update.TargetWPM != nil {
+		if next.TargetWPM = *update.TargetWPM; next.TargetWPM <= 0 {
+			return fmt.Errorf("target WPM must be above 0, got %g", next.TargetWPM)
+		}
+	}
+	if update.TargetBy != nil {
+		if next.TargetBy = *update.TargetBy; next.TargetBy == "" {
+			return fmt.Errorf("target date must be like 2026-12-31, got %q", next.TargetBy)
+		}
+	}
+	if err := next.validate(); err != nil {
+		return err
+	}
+	*g = next
+	return nil
+}
+
+func (g *Goals) Save() error {
+	return storage.Save(goalsFile, g)
+}
+
+func (g *Goals) Empty() bool {
+	return g.DailyMinutes == 0 && g.DailyRaces == 0 && g.TargetWPM == 0
+}
+
+// Deadline returns the end of the TargetBy day, if one is set.
+func (g *Goals) Deadline() (time.Time, bool) {
+	if g.TargetBy == "" {
+		return time.Time{}, false
+	}
+	by, err := time.ParseInLocation(dateFormat, g.TargetBy, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return by.AddDate(0, 0, 1), true
+}
+
+// Day is what was practised on one day.
+type Day struct {
+	Races    int
+	Duration time.Duration
+}
+
+func (g *Goals) met(d Day) bool {
+	if g.DailyMinutes == 0 && g.DailyRaces == 0 {
+		return d.Races > 0
+	}
+	return d.Duration >= time.Duration(g.DailyMinutes)*time.Minute && d.Races >= g.DailyRaces
+}
+
+// Progress is where the user stands today.
+type Progress struct {
+	Today   Day
+	Streak  int
+	Longest int
+	// Recent is the average WPM of the latest races.
+	Recent float64
+}
+
+func midnight(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// Check works out today's progress and the streaks from the history. The
+// current streak survives until the end of a day on which the goals have
+// not been met yet.
+func (g *Goals) Check(records []history.Record, now time.Time) Progress {
+	days := make(map[time.Time]Day)
+	for _, record := range records {
+		key := midnight(record.Time)
+		d := days[key]
+		d.Races++
+		d.Duration += record.Stats.Duration
+		days[key] = d
+	}
+
+	today := midnight(now)
+	progress := Progress{Today: days[today]}
+
+	var first time.Time
+	for day := range days {
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+
+	// No streak reaches back before the first race.
+	for day := today; !first.IsZero() && !day.Before(first); day = day.AddDate(0, 0, -1) {
+		if g.met(days[day]) {
+			progress.Streak++
+		} else if !day.Equal(today) {
+			break
+		}
+	}
+
+	run := 0
+	for day := first; !first.IsZero() && !day.After(today); day = day.AddDate(0, 0, 1) {
+		if g.met(days[day]) {
+			run++
+			progress.Longest = max(progress.Longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	from := max(0, len(records)-recentRaces)
+	for _, record := range records[from:] {
+		progress.Recent += record.Stats.WPM
+	}
+	if n := len(records) - from; n > 0 {
+		progress.Recent /= float64(n)
+	}
+	return progress
+}
@@ -0,0 +1,148 @@
+package goal
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 3, 10, 18, 0, 0, 0, time.Local)
+	played := func(daysAgo int, minutes float64, wpm float64) history.Record {
+		return history.Record{
+			Time:  now.AddDate(0, 0, -daysAgo),
+			Stats: race.Stats{Duration: time.Duration(minutes * float64(time.Minute)), WPM: wpm},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		goals   Goals
+		records []history.Record
+		want    Progress
+	}{
+		{
+			name: "no races",
+			want: Progress{},
+		},
+		{
+			name:    "any race without goals",
+			records: []history.Record{played(2, 1, 40), played(1, 1, 50), played(0, 1, 60)},
+			want:    Progress{Today: Day{Races: 1, Duration: time.Minute}, Streak: 3, Longest: 3, Recent: 50},
+		},
+		{
+			name:    "streak survives until today ends",
+			records: []history.Record{played(2, 1, 40), played(1, 1, 40)},
+			want:    Progress{Streak: 2, Longest: 2, Recent: 40},
+		},
+		{
+			name:    "gap breaks the streak",
+			records: []history.Record{played(5, 1, 40), played(4, 1, 40), played(3, 1, 40), played(1, 1, 40)},
+			want:    Progress{Streak: 1, Longest: 3, Recent: 40},
+		},
+		{
+			name:    "daily minutes",
+			goals:   Goals{DailyMinutes: 5},
+			records: []history.Record{played(1, 3, 40), played(1, 3, 40), played(0, 3, 40)},
+			want:    Progress{Today: Day{Races: 1, Duration: 3 * time.Minute}, Streak: 1, Longest: 1, Recent: 40},
+		},
+		{
+			name:    "daily races",
+			goals:   Goals{DailyRaces: 2},
+			records: []history.Record{played(2, 1, 40), played(2, 1, 40), played(1, 1, 40), played(0, 1, 40), played(0, 1, 40)},
+			want:    Progress{Today: Day{Races: 2, Duration: 2 * time.Minute}, Streak: 1, Longest: 1, Recent: 40},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.goals.Check(tt.records, now)
+			if got.Today != tt.want.Today || got.Streak != tt.want.Streak || got.Longest != tt.want.Longest ||
+				math.Abs(got.Recent-tt.want.Recent) > 1e-9 {
+				t.Errorf("Check = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRecent(t *testing.T) {
+	now := time.Date(2026, 3, 10, 18, 0, 0, 0, time.Local)
+	var records []history.Record
+	for i := 0; i < recentRaces+5; i++ {
+		records = append(records, history.Record{Time: now, Stats: race.Stats{WPM: float64(i)}})
+	}
+	// Only the last ten races, 5 to 14, count.
+	if got := (&Goals{}).Check(records, now).Recent; got != 9.5 {
+		t.Errorf("Recent = %v, want 9.5", got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	count := func(n int) *int { return &n }
+	wpm := func(n float64) *float64 { return &n }
+	date := func(s string) *string { return &s }
+	current := Goals{DailyMinutes: 15, TargetWPM: 60, TargetBy: "2026-12-31"}
+
+	tests := []struct {
+		name   string
+		update Update
+		want   Goals
+		err    string
+	}{
+		{"nothing", Update{}, current, ""},
+		{"change one", Update{DailyRaces: count(10)}, Goals{DailyMinutes: 15, DailyRaces: 10, TargetWPM: 60, TargetBy: "2026-12-31"}, ""},
+		{"change several", Update{DailyMinutes: count(20), TargetWPM: wpm(80.5)}, Goals{DailyMinutes: 20, TargetWPM: 80.5, TargetBy: "2026-12-31"}, ""},
+		{"zero minutes", Update{DailyMinutes: count(0)}, current, "daily minutes must be above 0, got 0"},
+		{"negative minutes", Update{DailyMinutes: count(-5)}, current, "daily minutes must be above 0, got -5"},
+		{"zero races", Update{DailyRaces: count(0)}, current, "daily races must be above 0, got 0"},
+		{"negative races", Update{DailyRaces: count(-1)}, current, "daily races must be above 0, got -1"},
+		{"zero WPM", Update{TargetWPM: wpm(0)}, current, "target WPM must be above 0, got 0"},
+		{"negative WPM", Update{TargetWPM: wpm(-80)}, current, "target WPM must be above 0, got -80"},
+		{"bad date", Update{TargetBy: date("31/12/2026")}, current, "target date must be like 2026-12-31"},
+		{"empty date", Update{TargetBy: date("")}, current, "target date must be like 2026-12-31"},
+		{"one bad value changes nothing", Update{DailyRaces: count(10), TargetWPM: wpm(0)}, current, "target WPM must be above 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goals := current
+			err := goals.Set(tt.update)
+			if (err == nil) != (tt.err == "") || err != nil && !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Set error = %v, want %q", err, tt.err)
+			}
+			if goals != tt.want {
+				t.Errorf("goals = %+v, want %+v", goals, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	t.Setenv("TYP0_HOME", t.TempDir())
+	t.Setenv("TYP0_PROFILE", "")
+
+	for _, goals := range []Goals{
+		{DailyMinutes: -1},
+		{DailyRaces: -3},
+		{TargetWPM: -80},
+		{TargetWPM: 80, TargetBy: "soon"},
+	} {
+		if err := storage.Save(goalsFile, goals); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(); err == nil {
+			t.Errorf("Load accepted %+v", goals)
+		}
+	}
+
+	valid := Goals{DailyRaces: 5, TargetWPM: 80, TargetBy: "2026-12-31"}
+	if err := valid.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if goals, err := Load(); err != nil || *goals != valid {
+		t.Errorf("Load = %+v, %v, want %+v", goals, err, valid)
+	}
+}
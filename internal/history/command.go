@@ -0,0 +1,153 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	var (
+		limit int
+		mode  string
+		tag   string
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, export or import saved races",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := Query{Mode: mode, Tag: strings.ToLower(tag), Last: limit}
+			if since != "" {
+				day, err := time.ParseInLocation("2006-01-02", since, time.Local)
+				if err != nil {
+					return fmt.Errorf("--since: want a date like 2024-01-31")
+				}
+				query.Since = day
+			}
+
+			records, err := Find(query)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				if mode != "" || tag != "" || since != "" {
+					fmt.Println("No races match.")
+					return nil
+				}
+				fmt.Println("No races saved yet. Start typing: typ0 race")
+				return nil
+			}
+
+			fmt.Printf("%-16s %-10s %8s %9s %8s  %s\n", "DATE", "MODE", "WPM", "ACCURACY", "TIME", "TAGS")
+			for _, record := range records {
+				fmt.Printf("%-16s %-10s %8.2f %8.2f%% %7.1fs  %s\n",
+					record.Time.Local().Format("2006-01-02 15:04"), record.Mode,
+					record.Stats.WPM, record.Stats.Accuracy, record.Stats.Duration.Seconds(),
+					strings.Join(record.Tags, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of most recent races to list (0 for all)")
+	cmd.Flags().StringVar(&mode, "mode", "", "Only list races of this mode")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only list races with this tag")
+	cmd.Flags().StringVar(&since, "since", "", "Only list races on or after this date (YYYY-MM-DD)")
+	cmd.AddCommand(newExportCommand(), newImportCommand())
+	return cmd
+}
+
+func newExportCommand() *cobra.Command {
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every saved race to a file",
+		Long: `Export the history with every recorded detail of each race. JSON and NDJSON
+carry a schema version; CSV has one row per race with scalar fields in
+their own columns and the rest JSON encoded.`,
+		Example: `  typ0 history export --format csv -o races.csv
+  typ0 history export --format ndjson > races.ndjson`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := Load()
+			if err != nil {
+				return err
+			}
+
+			if !slices.Contains(Formats, format) {
+				return fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+			}
+			if output == "" || output == "-" {
+				return Export(os.Stdout, format, records)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err := Export(f, format, records); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d races to %s\n", len(records), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "json", "Output format: "+strings.Join(Formats, ", "))
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write instead of standard output")
+	return cmd
+}
+
+func newImportCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Add races from an exported file",
+		Long: `Import races exported with "typ0 history export", or the CSV exports of
+Monkeytype (account settings) and TypeRacer race history. Races from other
+tools are saved with the tool as their mode and count towards trends and
+streaks. Races already in the history are skipped, so importing the same
+file twice is harmless.`,
+		Example: `  typ0 history import races.json
+  typ0 history import results.csv --format monkeytype
+  typ0 history import race_data.csv --format typeracer`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if format == "" {
+				format = DetectFormat(path, data)
+			}
+
+			records, err := Parse(data, format)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			added, err := Merge(records)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d races (%d already in the history)\n", len(added), len(records)-len(added))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Input format: "+strings.Join(ImportFormats, ", ")+" (detected when not given)")
+	return cmd
+}
@@ -0,0 +1,291 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The history lives in an embedded database with these buckets:
+//
+//	meta        schema version
+//	races       race ID -> Record without its keystrokes
+//	keystrokes  race ID -> the race's keystroke log
+//	by_time     end time + race ID
+//	by_mode     mode + 0 + end time + race ID
+//	by_tag      tag + 0 + end time + race ID, once per tag
+//	keys        rune -> race.KeyStat totals over every race
+//
+// Index entries have empty values. End times are nanoseconds since 1970
+// with the sign bit flipped, big-endian, so keys sort in time order even
+// before 1970.
+const (
+	dbFile = "typ0.db"
+	// timeout is how long to wait for another typ0 to close the database.
+	timeout = time.Second
+)
+
+var (
+	metaBucket       = []byte("meta")
+	racesBucket      = []byte("races")
+	keystrokesBucket = []byte("keystrokes")
+	byTimeBucket     = []byte("by_time")
+	byModeBucket     = []byte("by_mode")
+	byTagBucket      = []byte("by_tag")
+	keysBucket       = []byte("keys")
+
+	schemaKey = []byte("schema")
+)
+
+// openDB opens the database of the active profile, bringing its schema up
+// to date first.
+func openDB() (*bolt.DB, error) {
+	path, err := storage.Path(dbFile)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, dbError(path, err)
+	}
+
+	if _, err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func dbError(path string, err error) error {
+	if errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("%s is in use by another typ0", path)
+	}
+	return err
+}
+
+// Times outside the years 1678 to 2262 have no UnixNano and are clamped,
+// so imported races with missing dates sort first.
+var (
+	earliestTime = time.Unix(0, math.MinInt64)
+	latestTime   = time.Unix(0, math.MaxInt64)
+)
+
+func timeKey(t time.Time) []byte {
+	ns := t.UnixNano()
+	if t.Before(earliestTime) {
+		ns = math.MinInt64
+	} else if t.After(latestTime) {
+		ns = math.MaxInt64
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(ns)^1<<63)
+	return key
+}
+
+func byTimeKey(record Record) []byte {
+	return append(timeKey(record.Time), record.ID...)
+}
+
+func byModeKey(record Record) []byte {
+	return prefixed(record.Mode, record)
+}
+
+func byTagKeys(record Record) [][]byte {
+	keys := make([][]byte, len(record.Tags))
+	for i, tag := range record.Tags {
+		keys[i] = prefixed(tag, record)
+	}
+	return keys
+}
+
+func prefixed(prefix string, record Record) []byte {
+	key := append([]byte(prefix), 0)
+	return append(key, byTimeKey(record)...)
+}
+
+// put stores a new race with its index entries and adds its keystrokes to
+// the per-key totals. Races already stored are left alone; it reports
+// whether record was new.
+func put(tx *bolt.Tx, record Record) (bool, error) {
+	races := tx.Bucket(racesBucket)
+	if races.Get([]byte(record.ID)) != nil {
+		return false, nil
+	}
+
+	keystrokes := record.Stats.Keystrokes
+	record.Stats.Keystrokes = nil
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	if err := races.Put([]byte(record.ID), data); err != nil {
+		return false, err
+	}
+	if len(keystrokes) > 0 {
+		data, err := json.Marshal(keystrokes)
+		if err != nil {
+			return false, err
+		}
+		if err := tx.Bucket(keystrokesBucket).Put([]byte(record.ID), data); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Bucket(byTimeBucket).Put(byTimeKey(record), nil); err != nil {
+		return false, err
+	}
+	if err := tx.Bucket(byModeBucket).Put(byModeKey(record), nil); err != nil {
+		return false, err
+	}
+	for _, key := range byTagKeys(record) {
+		if err := tx.Bucket(byTagBucket).Put(key, nil); err != nil {
+			return false, err
+		}
+	}
+
+	record.Stats.Keystrokes = keystrokes
+	return true, addKeyTotals(tx.Bucket(keysBucket), record.Stats.KeyStats())
+}
+
+func addKeyTotals(bucket *bolt.Bucket, keys map[rune]race.KeyStat) error {
+	for r, stat := range keys {
+		key := []byte(string(r))
+		var total race.KeyStat
+		if data := bucket.Get(key); data != nil {
+			if err := json.Unmarshal(data, &total); err != nil {
+				return fmt.Errorf("key totals for %q: %w", r, err)
+			}
+		}
+		total.Add(stat)
+		data, err := json.Marshal(total)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get reads a race and its keystrokes.
+func get(tx *bolt.Tx, id []byte) (Record, error) {
+	var record Record
+	data := tx.Bucket(racesBucket).Get(id)
+	if data == nil {
+		return record, fmt.Errorf("race %s is indexed but missing", id)
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("race %s: %w", id, err)
+	}
+	if data := tx.Bucket(keystrokesBucket).Get(id); data != nil {
+		if err := json.Unmarshal(data, &record.Stats.Keystrokes); err != nil {
+			return record, fmt.Errorf("race %s keystrokes: %w", id, err)
+		}
+	}
+	return record, nil
+}
+
+// Query selects races from the history. Zero fields match everything.
+type Query struct {
+	Mode  string
+	Tag   string
+	Since time.Time
+	// Until is exclusive.
+	Until time.Time
+	// Last keeps only the most recent races.
+	Last int
+}
+
+// Find returns the races matching q, oldest first, using the date, mode and
+// tag indices so only those races are read.
+func Find(q Query) ([]Record, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var records []Record
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket, prefix := tx.Bucket(byTimeBucket), []byte(nil)
+		if q.Tag != "" {
+			bucket, prefix = tx.Bucket(byTagBucket), append([]byte(q.Tag), 0)
+		} else if q.Mode != "" {
+			bucket, prefix = tx.Bucket(byModeBucket), append([]byte(q.Mode), 0)
+		}
+
+		start := prefix
+		if !q.Since.IsZero() {
+			start = append(bytes.Clone(prefix), timeKey(q.Since)...)
+		}
+		var end []byte
+		if !q.Until.IsZero() {
+			end = append(bytes.Clone(prefix), timeKey(q.Until)...)
+		}
+
+		var ids [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			ids = append(ids, k[len(prefix)+8:])
+		}
+		// With both a tag and a mode, the tag index narrows the races down
+		// and the mode is checked on each.
+		filter := q.Tag != "" && q.Mode != ""
+		if q.Last > 0 && len(ids) > q.Last && !filter {
+			ids = ids[len(ids)-q.Last:]
+		}
+
+		records = make([]Record, 0, len(ids))
+		for _, id := range ids {
+			record, err := get(tx, id)
+			if err != nil {
+				return err
+			}
+			if filter && record.Mode != q.Mode {
+				continue
+			}
+			records = append(records, record)
+		}
+		if q.Last > 0 && len(records) > q.Last {
+			records = records[len(records)-q.Last:]
+		}
+		return nil
+	})
+	return records, err
+}
+
+// KeyTotals returns the per-key figures of every saved race.
+func KeyTotals() (map[rune]race.KeyStat, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	totals := make(map[rune]race.KeyStat)
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(keysBucket).ForEach(func(k, v []byte) error {
+			var stat race.KeyStat
+			if err := json.Unmarshal(v, &stat); err != nil {
+				return fmt.Errorf("key totals for %q: %w", k, err)
+			}
+			totals[[]rune(string(k))[0]] = stat
+			return nil
+		})
+	})
+	return totals, err
+}
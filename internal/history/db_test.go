@@ -0,0 +1,252 @@
+package history
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func useTempHome(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TYP0_HOME", dir)
+	t.Setenv("TYP0_PROFILE", "")
+	return dir
+}
+
+func TestTimeKeyOrder(t *testing.T) {
+	times := []time.Time{
+		{},
+		time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Unix(0, 0),
+		time.Unix(0, 1),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 0, 0, 0, 1, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i := 1; i < len(times); i++ {
+		if bytes.Compare(timeKey(times[i-1]), timeKey(times[i])) >= 0 {
+			t.Errorf("key of %v does not sort before key of %v", times[i-1], times[i])
+		}
+	}
+}
+
+func record(id string, at time.Time, mode string, tags ...string) Record {
+	return Record{
+		ID:   id,
+		Time: at,
+		Mode: mode,
+		Tags: tags,
+		Stats: race.Stats{
+			Text:     "ab",
+			Input:    "ab",
+			Finished: true,
+			Keystrokes: []race.Keystroke{
+				{Index: 0, Expected: 'a', Typed: 'a', At: 100 * time.Millisecond},
+				{Index: 1, Expected: 'b', Typed: 'b', At: 300 * time.Millisecond},
+			},
+		},
+	}
+}
+
+func ids(records []Record) []string {
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+func TestFind(t *testing.T) {
+	useTempHome(t)
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	if err := Append(
+		record("c", day(3), "words", "split"),
+		record("a", day(1), "words", "laptop"),
+		record("old", time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), Monkeytype),
+		record("undated", time.Time{}, TypeRacer),
+		record("b", day(2), "quote", "split"),
+		record("d", day(4), "quote", "split", "laptop"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	// Appending again adds nothing.
+	if err := Append(record("a", day(1), "words", "laptop")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"undated", "old", "a", "b", "c", "d"}},
+		{"mode", Query{Mode: "quote"}, []string{"b", "d"}},
+		{"tag", Query{Tag: "split"}, []string{"b", "c", "d"}},
+		{"tag and mode", Query{Tag: "split", Mode: "quote"}, []string{"b", "d"}},
+		{"since", Query{Since: day(2)}, []string{"b", "c", "d"}},
+		{"until", Query{Until: day(2)}, []string{"undated", "old", "a"}},
+		{"range", Query{Since: day(1), Until: day(4)}, []string{"a", "b", "c"}},
+		{"last", Query{Last: 2}, []string{"c", "d"}},
+		{"last with tag and mode", Query{Tag: "split", Mode: "quote", Last: 1}, []string{"d"}},
+		{"no match", Query{Mode: "learn"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Find(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(records); !slices.Equal(got, tt.want) {
+				t.Errorf("Find(%+v) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	records, err := Find(Query{Last: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || len(records[0].Stats.Keystrokes) != 2 {
+		t.Errorf("keystrokes were not stored with the race: %+v", records)
+	}
+
+	totals, err := KeyTotals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals['a'].Presses != 6 || totals['b'].Presses != 6 {
+		t.Errorf("key totals = %+v, want 6 presses of a and b", totals)
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	useTempHome(t)
+	status, applied, err := Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(Migrations()) || status.Schema != LatestSchema() || status.Races != 0 {
+		t.Errorf("applied %d migrations to reach schema %d with %d races, want %d, %d and none",
+			len(applied), status.Schema, status.Races, len(Migrations()), LatestSchema())
+	}
+	for i, m := range Migrations() {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d", i+1, m.Version)
+		}
+	}
+
+	if _, applied, err := Migrate(); err != nil || len(applied) != 0 {
+		t.Errorf("second Migrate applied %d migrations (%v), want none", len(applied), err)
+	}
+}
+
+// withDB opens the database directly, below the migrations.
+func withDB(t *testing.T, fn func(tx *bolt.Tx) error) {
+	t.Helper()
+	path, err := storage.Path(dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(path, 0o644, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Update(fn); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateRebuildsIndices(t *testing.T) {
+	useTempHome(t)
+	if err := Append(record("a", time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), "words", "split")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Roll back to schema 3, before races were indexed by tag.
+	withDB(t, func(tx *bolt.Tx) error {
+		if err := tx.Bucket(byTagBucket).Put([]byte("stale\x00entry"), nil); err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucket).Put(schemaKey, []byte("3"))
+	})
+
+	status, applied, err := Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != 4 || status.Schema != LatestSchema() {
+		t.Errorf("applied %+v to reach schema %d", applied, status.Schema)
+	}
+	if _, problems, err := Check(false); err != nil || len(problems) > 0 {
+		t.Errorf("Check after migrating = %q, %v", problems, err)
+	}
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	useTempHome(t)
+	if _, _, err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	withDB(t, func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(schemaKey, []byte(strconv.Itoa(LatestSchema()+1)))
+	})
+	if _, err := Load(); err == nil {
+		t.Error("Load of a newer schema succeeded")
+	}
+}
+
+func TestCheckRepair(t *testing.T) {
+	useTempHome(t)
+	if err := Append(
+		record("a", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "words", "split"),
+		record("b", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "quote"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, problems, err := Check(false); err != nil || len(problems) > 0 {
+		t.Fatalf("Check of a new database = %q, %v", problems, err)
+	}
+
+	withDB(t, func(tx *bolt.Tx) error {
+		if err := tx.Bucket(byModeBucket).Put([]byte("gone\x00stale"), nil); err != nil {
+			return err
+		}
+		if err := tx.Bucket(byTagBucket).Delete(byTagKeys(record("a", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "words", "split"))[0]); err != nil {
+			return err
+		}
+		if err := tx.Bucket(keysBucket).Put([]byte("a"), []byte(`{"presses":99}`)); err != nil {
+			return err
+		}
+		return tx.Bucket(keystrokesBucket).Put([]byte("orphan"), []byte("[]"))
+	})
+
+	_, problems, err := Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The stale mode entry, the missing tag entry, the key totals and the
+	// orphaned keystrokes.
+	if len(problems) != 4 {
+		t.Errorf("Check found %q, want 4 problems", problems)
+	}
+
+	// Orphaned keystrokes are left for the user to look at; the rest is
+	// repaired.
+	_, problems, err = Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 {
+		t.Errorf("Check after repair found %q, want only the orphaned keystrokes", problems)
+	}
+}
@@ -0,0 +1,262 @@
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Races imported from other typing tools keep the tool's name as their
+// mode and have no text or keystrokes, only the summary figures.
+const (
+	Monkeytype = "monkeytype"
+	TypeRacer  = "typeracer"
+)
+
+// monkeytypeLangs maps Monkeytype's language names to language pack codes.
+// Variants such as "english_1k" share the code of their base language.
+var monkeytypeLangs = map[string]string{
+	"english": "en", "german": "de", "spanish": "es", "french": "fr",
+	"portuguese": "pt", "polish": "pl",
+}
+
+// warn reports a problem that does not stop an import.
+var warn = func(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
+
+// monkeytypeLang returns the language pack code for a Monkeytype language
+// name, or "" when there is no such pack.
+func monkeytypeLang(name string) string {
+	name = strings.ToLower(name)
+	if code, ok := monkeytypeLangs[name]; ok {
+		return code
+	}
+	base, _, _ := strings.Cut(name, "_")
+	return monkeytypeLangs[base]
+}
+
+// externalID identifies an imported race by the tool and its own ID for
+// it, so importing the same export twice adds nothing.
+func externalID(tool, id string) string {
+	sum := sha256.Sum256([]byte(tool + "\x00" + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// table reads a CSV file and finds columns by any of their known names,
+// ignoring case.
+type table struct {
+	rows  [][]string
+	index map[string]int
+}
+
+func readTable(data []byte) (*table, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	t := &table{rows: rows[1:], index: make(map[string]int)}
+	for i, name := range rows[0] {
+		t.index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return t, nil
+}
+
+func (t *table) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := t.index[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *table) cell(row []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := t.index[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+	}
+	return ""
+}
+
+func (t *table) number(row []string, names ...string) (float64, error) {
+	v := strings.TrimSuffix(t.cell(row, names...), "%")
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// ParseMonkeytype reads the results CSV exported from Monkeytype's account
+// settings.
+func ParseMonkeytype(data []byte) ([]Record, error) {
+	t, err := readTable(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range []string{"wpm", "acc", "timestamp"} {
+		if !t.has(column) {
+			return nil, fmt.Errorf("not a Monkeytype export: no %q column", column)
+		}
+	}
+
+	var records []Record
+	unknown := make(map[string]int)
+	for n, row := range t.rows {
+		record, err := monkeytypeRecord(t, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", n+2, err)
+		}
+		if language := t.cell(row, "language"); language != "" && record.Lang == "" {
+			unknown[language]++
+		}
+		records = append(records, record)
+	}
+
+	languages := make([]string, 0, len(unknown))
+	for language := range unknown {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	for _, language := range languages {
+		warn("no language pack for Monkeytype language %q; %d races imported without a language", language, unknown[language])
+	}
+	return records, nil
+}
+
+func monkeytypeRecord(t *table, row []string) (Record, error) {
+	var record Record
+	ms, err := strconv.ParseInt(t.cell(row, "timestamp"), 10, 64)
+	if err != nil {
+		return record, fmt.Errorf("timestamp: %w", err)
+	}
+	wpm, err := t.number(row, "wpm")
+	if err != nil {
+		return record, fmt.Errorf("wpm: %w", err)
+	}
+	accuracy, err := t.number(row, "acc")
+	if err != nil {
+		return record, fmt.Errorf("acc: %w", err)
+	}
+	seconds, err := t.number(row, "testduration")
+	if err != nil {
+		return record, fmt.Errorf("testDuration: %w", err)
+	}
+
+	record = Record{
+		Time: time.UnixMilli(ms).UTC(),
+		Mode: Monkeytype,
+	}
+	record.Lang = monkeytypeLang(t.cell(row, "language"))
+	if t.cell(row, "mode") == "words" {
+		record.WordCount, _ = strconv.Atoi(t.cell(row, "mode2"))
+	}
+	record.Stats.WPM = wpm
+	record.Stats.Accuracy = accuracy
+	record.Stats.Duration = time.Duration(seconds * float64(time.Second))
+	record.Stats.Finished = t.cell(row, "bailedout") != "true"
+
+	id := t.cell(row, "_id")
+	if id == "" {
+		id = strconv.FormatInt(ms, 10)
+	}
+	record.ID = externalID(Monkeytype, id)
+	return record, nil
+}
+
+var typeRacerTimes = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"1/2/2006 15:04",
+	"2006-01-02",
+}
+
+// ParseTypeRacer reads a TypeRacer race history CSV. Column names vary
+// between exports, so the common spellings are accepted.
+func ParseTypeRacer(data []byte) ([]Record, error) {
+	t, err := readTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if !t.has("wpm", "speed", "speed (wpm)") {
+		return nil, fmt.Errorf("not a TypeRacer export: no WPM column")
+	}
+	if !t.has("date/time (utc)", "date", "date/time", "time", "timestamp") {
+		return nil, fmt.Errorf("not a TypeRacer export: no date column")
+	}
+
+	var records []Record
+	for n, row := range t.rows {
+		record, err := typeRacerRecord(t, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", n+2, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func typeRacerRecord(t *table, row []string) (Record, error) {
+	var record Record
+	when := t.cell(row, "date/time (utc)", "date", "date/time", "time", "timestamp")
+	at, err := parseTime(when)
+	if err != nil {
+		return record, err
+	}
+	wpm, err := t.number(row, "wpm", "speed", "speed (wpm)")
+	if err != nil {
+		return record, fmt.Errorf("wpm: %w", err)
+	}
+	accuracy, err := t.number(row, "accuracy", "acc")
+	if err != nil {
+		return record, fmt.Errorf("accuracy: %w", err)
+	}
+	// Some exports give accuracy as a fraction.
+	if accuracy > 0 && accuracy <= 1 {
+		accuracy *= 100
+	}
+
+	record = Record{Time: at, Mode: TypeRacer}
+	record.Stats.WPM = wpm
+	record.Stats.Accuracy = accuracy
+	record.Stats.Finished = true
+
+	id := t.cell(row, "race #", "race", "race number", "id")
+	if id == "" {
+		id = at.Format(time.RFC3339Nano)
+	}
+	record.ID = externalID(TypeRacer, id)
+	return record, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if seconds > 1e11 {
+			return time.UnixMilli(seconds).UTC(), nil
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	for _, layout := range typeRacerTimes {
+		if at, err := time.Parse(layout, s); err == nil {
+			return at.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
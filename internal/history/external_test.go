@@ -0,0 +1,174 @@
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonkeytypeLang(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"english", "en"},
+		{"English", "en"},
+		{"english_1k", "en"},
+		{"english_commonly_misspelled", "en"},
+		{"german_10k", "de"},
+		{"polish", "pl"},
+		{"italian", ""},
+		{"code_python", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := monkeytypeLang(tt.name); got != tt.want {
+			t.Errorf("monkeytypeLang(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseMonkeytype(t *testing.T) {
+	var warnings []string
+	defer func(w func(string, ...any)) { warn = w }(warn)
+	warn = func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	data := "\xef\xbb\xbf_id,isPb,wpm,acc,rawWpm,consistency,charStats,mode,mode2,quoteLength,restartCount,testDuration,afkDuration,incompleteTestSeconds,punctuation,numbers,language,funbox,difficulty,lazyMode,blindMode,bailedOut,tags,timestamp\n" +
+		"a1,true,92.4,97.5,95,80,\"100;2;0;0\",words,25,-1,0,16.23,0,0,false,false,english_1k,none,normal,false,false,false,,1700000000000\n" +
+		"a2,false,61,88,70,70,\"1;1;0;0\",time,30,-1,0,30,0,0,false,false,italian,none,normal,false,false,true,,1700000100000\n" +
+		"a3,false,70,90,75,70,\"1;1;0;0\",time,15,-1,0,15,0,0,false,false,italian,none,normal,false,false,false,,1700000200000\n"
+
+	records, err := ParseMonkeytype([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	first := records[0]
+	want := Record{
+		ID:        externalID(Monkeytype, "a1"),
+		Time:      time.UnixMilli(1700000000000).UTC(),
+		Mode:      Monkeytype,
+		Lang:      "en",
+		WordCount: 25,
+	}
+	want.Stats.WPM = 92.4
+	want.Stats.Accuracy = 97.5
+	want.Stats.Duration = 16230 * time.Millisecond
+	want.Stats.Finished = true
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("first record = %+v, want %+v", first, want)
+	}
+
+	if records[1].Lang != "" || records[1].WordCount != 0 || records[1].Stats.Finished {
+		t.Errorf("second record = %+v, want no language or word count and not finished", records[1])
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want one for italian", warnings)
+	}
+}
+
+func TestParseMonkeytypeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"missing column", "_id,wpm,timestamp\na,50,1\n"},
+		{"bad timestamp", "_id,wpm,acc,timestamp\na,50,90,yesterday\n"},
+		{"bad wpm", "_id,wpm,acc,timestamp\na,fast,90,1\n"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseMonkeytype([]byte(tt.data)); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestParseTypeRacer(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		at       time.Time
+		wpm      float64
+		accuracy float64
+		id       string
+	}{
+		{
+			name:     "race history",
+			data:     "Race #,WPM,Accuracy,Rank,# Racers,Text ID,Date/Time (UTC)\n812,88.5,98%,1,5,3550123,2024-01-31 20:15:03\n",
+			at:       time.Date(2024, 1, 31, 20, 15, 3, 0, time.UTC),
+			wpm:      88.5,
+			accuracy: 98,
+			id:       "812",
+		},
+		{
+			name:     "fractional accuracy",
+			data:     "race,speed,accuracy,date\n7,70,0.95,2024-02-01T08:00:00Z\n",
+			at:       time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+			wpm:      70,
+			accuracy: 95,
+			id:       "7",
+		},
+		{
+			name:     "unix timestamp without race number",
+			data:     "wpm,accuracy,timestamp\n64,91,1700000000\n",
+			at:       time.Unix(1700000000, 0).UTC(),
+			wpm:      64,
+			accuracy: 91,
+			id:       time.Unix(1700000000, 0).UTC().Format(time.RFC3339Nano),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ParseTypeRacer([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1", len(records))
+			}
+			r := records[0]
+			if !r.Time.Equal(tt.at) || r.Stats.WPM != tt.wpm || r.Stats.Accuracy != tt.accuracy ||
+				r.Mode != TypeRacer || !r.Stats.Finished || r.ID != externalID(TypeRacer, tt.id) {
+				t.Errorf("record = %+v", r)
+			}
+		})
+	}
+
+	for _, data := range []string{
+		"race,date\n1,2024-01-01\n",
+		"race,wpm\n1,50\n",
+		"race,wpm,date\n1,50,last tuesday\n",
+	} {
+		if _, err := ParseTypeRacer([]byte(data)); err == nil {
+			t.Errorf("ParseTypeRacer(%q): no error", data)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want string
+	}{
+		{"results.csv", "_id,isPb,wpm,acc,timestamp\n", Monkeytype},
+		{"races.csv", "Race #,WPM,Accuracy,Date/Time (UTC)\n", TypeRacer},
+		{"history.csv", "schema_version,id,time,mode,race\n", "csv"},
+		{"history.json", `{"schema":"typ0-history","records":[]}`, "json"},
+		{"history.ndjson", `{"id":"a"}`, "ndjson"},
+		{"stdin", "[]", "json"},
+		{"stdin", "{\"id\":\"a\"}\n{\"id\":\"b\"}\n", "ndjson"},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go-typ0/internal/difficulty"
+	"go-typ0/internal/race"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Record is one finished race as kept in the history.
+type Record struct {
+	ID        string     `json:"id"`
+	Time      time.Time  `json:"time"`
+	Mode      string     `json:"mode"`
+	Lang      string     `json:"lang,omitempty"`
+	Layout    string     `json:"layout,omitempty"`
+	WordCount int        `json:"word_count,omitempty"`
+	Level     string     `json:"level,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Stats     race.Stats `json:"stats"`
+}
+
+// Meta describes how the races of a session were set up.
+type Meta struct {
+	Mode      string
+	Lang      string
+	Layout    string
+	WordCount int
+	Level     string
+	Tags      []string
+}
+
+func NewMeta(mode string, opts race.Options) Meta {
+	meta := Meta{Mode: mode, Layout: opts.LayoutName(), Tags: opts.Tags}
+	if opts.Difficulty != difficulty.Any {
+		meta.Level = opts.Difficulty.String()
+	}
+	// The word count only sets the length of random word texts.
+	if opts.Source == nil && !opts.Quote {
+		meta.WordCount = opts.WordCount
+	}
+	if opts.Pack != nil {
+		meta.Lang = opts.Pack.Code
+	}
+	return meta
+}
+
+func NewRecord(meta Meta, stats race.Stats, at time.Time) Record {
+	record := Record{
+		Time:      at.UTC(),
+		Mode:      meta.Mode,
+		Lang:      meta.Lang,
+		Layout:    meta.Layout,
+		WordCount: meta.WordCount,
+		Level:     meta.Level,
+		Tags:      meta.Tags,
+		Stats:     stats,
+	}
+	record.ID = record.fingerprint()
+	return record
+}
+
+// fingerprint identifies a race by when it ended and what was typed, so
+// the same race imported twice gets the same ID.
+func (r Record) fingerprint() string {
+	sum := sha256.Sum256([]byte(r.Time.Format(time.RFC3339Nano) + "\x00" + r.Stats.Text + "\x00" + r.Stats.Input))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Append saves races to the history, skipping any it already has.
+func Append(records ...Record) error {
+	_, err := appendNew(records)
+	return err
+}
+
+// appendNew saves records like Append and returns the ones that were new.
+func appendNew(records []Record) ([]Record, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var added []Record
+	err = db.Update(func(tx *bolt.Tx) error {
+		added = nil
+		for _, record := range records {
+			isNew, err := put(tx, record)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				added = append(added, record)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Load returns every saved race, oldest first.
+func Load() ([]Record, error) {
+	return Find(Query{})
+}
+
+func AllStats(records []Record) []race.Stats {
+	stats := make([]race.Stats, len(records))
+	for i, record := range records {
+		stats[i] = record.Stats
+	}
+	return stats
+}
+
+// Attach saves every race vm finishes, along with the word totals, and
+// feeds past races back to its results screen. It is a race.RecorderFunc.
+// Problems reading or writing the history do not interrupt the race; the
+// returned function reports them afterwards.
+func Attach(vm *race.ViewModel, mode string, opts race.Options) func() error {
+	meta := NewMeta(mode, opts)
+	words, err := LoadWords(Load)
+	keys, keysErr := KeyTotals()
+	if err == nil {
+		err = keysErr
+	}
+
+	// The races themselves are only read once the results screen asks for
+	// them.
+	var (
+		records []Record
+		loaded  bool
+	)
+	vm.OnFinish(func(stats race.Stats) {
+		record := NewRecord(meta, stats, time.Now())
+		if loaded {
+			records = append(records, record)
+		}
+		if keys != nil {
+			for r, stat := range stats.KeyStats() {
+				total := keys[r]
+				total.Add(stat)
+				keys[r] = total
+			}
+		}
+		if appendErr := Append(record); appendErr != nil {
+			err = appendErr
+		}
+		if words != nil {
+			words.Add(stats)
+			if saveErr := words.Save(); saveErr != nil {
+				err = saveErr
+			}
+		}
+	})
+	vm.SetHistory(func() []race.Stats {
+		if !loaded {
+			loaded = true
+			var loadErr error
+			if records, loadErr = Load(); loadErr != nil {
+				err = loadErr
+			}
+		}
+		return AllStats(records)
+	})
+	if words != nil {
+		vm.SetWordTotals(func() map[string]race.WordTotal {
+			return words.Totals
+		})
+	}
+	if keys != nil {
+		vm.SetKeyTotals(func() map[rune]race.KeyStat {
+			return keys
+		})
+	}
+	return func() error { return err }
+}
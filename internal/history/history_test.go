@@ -0,0 +1,41 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+
+	"go-typ0/internal/difficulty"
+	"go-typ0/internal/layout"
+	"go-typ0/internal/race"
+	"go-typ0/internal/words"
+)
+
+func TestNewMeta(t *testing.T) {
+	colemak, err := layout.Load("colemak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	german := &words.Pack{Code: "de", Quotes: []string{"Ein Zitat."}}
+
+	tests := []struct {
+		name string
+		mode string
+		opts race.Options
+		want Meta
+	}{
+		{"words", "words", race.Options{WordCount: 25}, Meta{Mode: "words", WordCount: 25}},
+		{"quote", "quote", race.Options{WordCount: 25, Pack: german, Quote: true}, Meta{Mode: "quote", Lang: "de"}},
+		{"own text", "drill", race.Options{WordCount: 25, Source: func() string { return "asdf" }}, Meta{Mode: "drill"}},
+		{
+			"everything",
+			"words",
+			race.Options{WordCount: 10, Pack: german, Layout: colemak, Difficulty: difficulty.Hard, Tags: []string{"split"}},
+			Meta{Mode: "words", Lang: "de", Layout: "Colemak", WordCount: 10, Level: "hard", Tags: []string{"split"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := NewMeta(tt.mode, tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: NewMeta = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Migration moves the database from the schema before it to Version. Each
+// one runs in its own transaction, so a failure leaves the database at the
+// last version that completed. Migrations that move races are written
+// against the schema of their time and must not change once released. The
+// derived buckets, the indices and key totals, are different: they are
+// always rebuilt from the races with the current code, so a change to how
+// they are built needs a new migration that rebuilds them.
+type Migration struct {
+	Version     int
+	Description string
+	up          func(tx *bolt.Tx) error
+}
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create race and keystroke buckets",
+		up: func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{metaBucket, racesBucket, keystrokesBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "index races by date and mode",
+		up: func(tx *bolt.Tx) error {
+			return rebuild(tx, byTimeBucket, byModeBucket)
+		},
+	},
+	{
+		Version:     3,
+		Description: "add up per-key totals",
+		up: func(tx *bolt.Tx) error {
+			return rebuild(tx, keysBucket)
+		},
+	},
+	{
+		Version:     4,
+		Description: "index races by tag",
+		up: func(tx *bolt.Tx) error {
+			return rebuild(tx, byTagBucket)
+		},
+	},
+}
+
+// Migrations lists every schema migration in order.
+func Migrations() []Migration {
+	return migrations
+}
+
+// LatestSchema is the schema version this build of typ0 writes.
+func LatestSchema() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	meta := tx.Bucket(metaBucket)
+	if meta == nil {
+		return 0, nil
+	}
+	data := meta.Get(schemaKey)
+	if data == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("schema version %q: %w", data, err)
+	}
+	return version, nil
+}
+
+// migrate applies the migrations db has not had yet and returns them.
+func migrate(db *bolt.DB) ([]Migration, error) {
+	var current int
+	if err := db.View(func(tx *bolt.Tx) (err error) {
+		current, err = schemaVersion(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if current > LatestSchema() {
+		return nil, fmt.Errorf("%s has schema version %d, newer than this typ0 supports (%d); please upgrade", db.Path(), current, LatestSchema())
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := db.Update(func(tx *bolt.Tx) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Bucket(metaBucket).Put(schemaKey, []byte(strconv.Itoa(m.Version)))
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// rebuild empties the named derived buckets, the indices and key totals,
+// and fills them again from the races.
+func rebuild(tx *bolt.Tx, names ...[]byte) error {
+	buckets := make(map[string]*bolt.Bucket)
+	for _, name := range names {
+		if tx.Bucket(name) != nil {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		bucket, err := tx.CreateBucket(name)
+		if err != nil {
+			return err
+		}
+		buckets[string(name)] = bucket
+	}
+
+	return tx.Bucket(racesBucket).ForEach(func(id, _ []byte) error {
+		record, err := get(tx, id)
+		if err != nil {
+			return err
+		}
+		if bucket := buckets[string(byTimeBucket)]; bucket != nil {
+			if err := bucket.Put(byTimeKey(record), nil); err != nil {
+				return err
+			}
+		}
+		if bucket := buckets[string(byModeBucket)]; bucket != nil {
+			if err := bucket.Put(byModeKey(record), nil); err != nil {
+				return err
+			}
+		}
+		if bucket := buckets[string(byTagBucket)]; bucket != nil {
+			for _, key := range byTagKeys(record) {
+				if err := bucket.Put(key, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if bucket := buckets[string(keysBucket)]; bucket != nil {
+			return addKeyTotals(bucket, record.Stats.KeyStats())
+		}
+		return nil
+	})
+}
+
+// Status describes the database of the active profile.
+type Status struct {
+	Path   string
+	Size   int64
+	Schema int
+	Races  int
+}
+
+// Migrate brings the database up to date, creating it if needed, and
+// returns its status along with the migrations it applied.
+func Migrate() (Status, []Migration, error) {
+	path, err := storage.Path(dbFile)
+	if err != nil {
+		return Status{}, nil, err
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return Status{}, nil, dbError(path, err)
+	}
+	defer db.Close()
+
+	applied, err := migrate(db)
+	if err != nil {
+		return Status{}, applied, err
+	}
+	s, err := status(db)
+	return s, applied, err
+}
+
+func status(db *bolt.DB) (Status, error) {
+	s := Status{Path: db.Path()}
+	if info, err := os.Stat(s.Path); err == nil {
+		s.Size = info.Size()
+	}
+	err := db.View(func(tx *bolt.Tx) (err error) {
+		s.Races = tx.Bucket(racesBucket).Stats().KeyN
+		s.Schema, err = schemaVersion(tx)
+		return err
+	})
+	return s, err
+}
+
+// Check verifies the database file and that the indices and key totals
+// agree with the races. It returns the problems found; with repair, the
+// derived buckets are rebuilt afterwards.
+func Check(repair bool) (Status, []string, error) {
+	db, err := openDB()
+	if err != nil {
+		return Status{}, nil, err
+	}
+	defer db.Close()
+
+	var problems []string
+	err = db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			problems = append(problems, err.Error())
+		}
+
+		races := tx.Bucket(racesBucket)
+		expected := map[string]map[string]bool{
+			string(byTimeBucket): {},
+			string(byModeBucket): {},
+			string(byTagBucket):  {},
+		}
+		totals := make(map[rune]race.KeyStat)
+		if err := races.ForEach(func(id, _ []byte) error {
+			record, err := get(tx, id)
+			if err != nil {
+				problems = append(problems, err.Error())
+				return nil
+			}
+			if string(id) != record.ID {
+				problems = append(problems, fmt.Sprintf("race %s is stored under %s", record.ID, id))
+			}
+			expected[string(byTimeBucket)][string(byTimeKey(record))] = true
+			expected[string(byModeBucket)][string(byModeKey(record))] = true
+			for _, key := range byTagKeys(record) {
+				expected[string(byTagBucket)][string(key)] = true
+			}
+			for r, stat := range record.Stats.KeyStats() {
+				total := totals[r]
+				total.Add(stat)
+				totals[r] = total
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(keystrokesBucket).ForEach(func(id, _ []byte) error {
+			if races.Get(id) == nil {
+				problems = append(problems, fmt.Sprintf("keystrokes of missing race %s", id))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for name, keys := range expected {
+			found := 0
+			if err := tx.Bucket([]byte(name)).ForEach(func(k, _ []byte) error {
+				if !keys[string(k)] {
+					problems = append(problems, fmt.Sprintf("%s has a stale entry %x", name, k))
+					return nil
+				}
+				found++
+				return nil
+			}); err != nil {
+				return err
+			}
+			if found < len(keys) {
+				problems = append(problems, fmt.Sprintf("%s is missing %d entries", name, len(keys)-found))
+			}
+		}
+
+		stored := 0
+		if err := tx.Bucket(keysBucket).ForEach(func(k, v []byte) error {
+			stored++
+			var stat race.KeyStat
+			r := []rune(string(k))[0]
+			if err := json.Unmarshal(v, &stat); err != nil || stat != totals[r] {
+				problems = append(problems, fmt.Sprintf("key totals for %q do not match the races", r))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if stored != len(totals) {
+			problems = append(problems, fmt.Sprintf("key totals cover %d keys, the races %d", stored, len(totals)))
+		}
+		return nil
+	})
+	if err != nil {
+		return Status{}, problems, err
+	}
+
+	if repair && len(problems) > 0 {
+		if err := db.Update(func(tx *bolt.Tx) error {
+			return rebuild(tx, byTimeBucket, byModeBucket, byTagBucket, keysBucket)
+		}); err != nil {
+			return Status{}, problems, err
+		}
+	}
+	s, err := status(db)
+	return s, problems, err
+}
+
+// Vacuum rewrites the database without the free pages left behind by
+// deleted and replaced data. It returns the size before and after.
+func Vacuum() (before, after int64, err error) {
+	db, err := openDB()
+	if err != nil {
+		return 0, 0, err
+	}
+	path := db.Path()
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+	}()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	before = info.Size()
+
+	tmp := path + ".vacuum"
+	os.Remove(tmp)
+	compact, err := bolt.Open(tmp, 0o644, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(tmp)
+
+	err = bolt.Compact(compact, db, 64*1024*1024)
+	if closeErr := compact.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Windows cannot replace an open file.
+	db.Close()
+	db = nil
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, 0, err
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, info.Size(), nil
+}
@@ -0,0 +1,369 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-typ0/internal/race"
+)
+
+// SchemaVersion is the version of the exported history format. Files
+// without one are version 1.
+const (
+	SchemaVersion = 1
+	schemaName    = "typ0-history"
+)
+
+var (
+	Formats = []string{"json", "csv", "ndjson"}
+	// ImportFormats add exports of other typing tools.
+	ImportFormats = slices.Concat(Formats, []string{Monkeytype, TypeRacer})
+)
+
+type header struct {
+	Schema  string `json:"schema"`
+	Version int    `json:"version"`
+}
+
+type document struct {
+	header
+	Records []Record `json:"records"`
+}
+
+// csvColumns are the columns of a CSV export. Fields with structure are
+// JSON encoded in their cell.
+var csvColumns = []string{
+	"schema_version", "id", "time", "mode", "lang", "layout", "word_count",
+	"level", "tags", "duration_seconds", "wpm", "accuracy", "finished", "retry", "difficulty", "text", "input",
+	"mistyped", "confusions", "errors", "words", "fingers", "keystrokes",
+}
+
+func Export(w io.Writer, format string, records []Record) error {
+	switch format {
+	case "json":
+		if records == nil {
+			records = []Record{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document{header{schemaName, SchemaVersion}, records})
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(header{schemaName, SchemaVersion}); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return exportCSV(w, records)
+	}
+	return fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+}
+
+func exportCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		s := record.Stats
+		var cells []string
+		cells = append(cells,
+			strconv.Itoa(SchemaVersion), record.ID, record.Time.Format(time.RFC3339Nano),
+			record.Mode, record.Lang, record.Layout, strconv.Itoa(record.WordCount),
+			record.Level, strings.Join(record.Tags, ","),
+			strconv.FormatFloat(s.Duration.Seconds(), 'f', -1, 64),
+			strconv.FormatFloat(s.WPM, 'f', -1, 64),
+			strconv.FormatFloat(s.Accuracy, 'f', -1, 64),
+			strconv.FormatBool(s.Finished), strconv.FormatBool(s.Retry),
+			strconv.FormatFloat(s.Difficulty, 'f', -1, 64),
+			s.Text, s.Input,
+		)
+		for _, v := range []any{s.Mistyped, s.Confusions, s.Errors, s.Words, s.Fingers, s.Keystrokes} {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			cells = append(cells, string(data))
+		}
+		if err := writer.Write(cells); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// DetectFormat guesses the format of a file from its name, then from how
+// it starts.
+func DetectFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		header, _, _ := bytes.Cut(data, []byte("\n"))
+		header = bytes.ToLower(header)
+		switch {
+		case bytes.Contains(header, []byte("_id")) && bytes.Contains(header, []byte("acc")):
+			return Monkeytype
+		case bytes.Contains(header, []byte("race")) && !bytes.Contains(header, []byte("schema_version")):
+			return TypeRacer
+		}
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		return "json"
+	}
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if line, _, _ := bytes.Cut(trimmed, []byte("\n")); json.Valid(line) && !bytes.Contains(line, []byte(`"records"`)) {
+			return "ndjson"
+		}
+		return "json"
+	}
+	return "csv"
+}
+
+// Parse reads records written by Export, plain history files, or exports
+// of other typing tools.
+func Parse(data []byte, format string) ([]Record, error) {
+	switch format {
+	case "json":
+		return parseJSON(data)
+	case "ndjson":
+		return parseNDJSON(data)
+	case "csv":
+		return parseCSV(data)
+	case Monkeytype:
+		return ParseMonkeytype(data)
+	case TypeRacer:
+		return ParseTypeRacer(data)
+	}
+	return nil, fmt.Errorf("unknown format %q (use %s)", format, strings.Join(ImportFormats, ", "))
+}
+
+func checkVersion(h header) error {
+	if h.Schema != "" && h.Schema != schemaName {
+		return fmt.Errorf("not a typ0 history file (schema %q)", h.Schema)
+	}
+	if h.Version > SchemaVersion {
+		return fmt.Errorf("history schema version %d is newer than this typ0 supports (%d)", h.Version, SchemaVersion)
+	}
+	return nil
+}
+
+func parseJSON(data []byte) ([]Record, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var records []Record
+		return records, json.Unmarshal(data, &records)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Records, checkVersion(doc.header)
+}
+
+func parseNDJSON(data []byte) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if line == 1 && bytes.Contains(text, []byte(`"schema"`)) {
+			var h header
+			if err := json.Unmarshal(text, &h); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if err := checkVersion(h); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(text, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func parseCSV(data []byte) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	index := make(map[string]int)
+	for i, name := range rows[0] {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"time", "mode", "wpm"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV has no %q column", required)
+		}
+	}
+
+	var records []Record
+	for n, row := range rows[1:] {
+		record, err := parseCSVRow(row, index)
+		if err != nil {
+			return nil, fmt.Errorf("CSV row %d: %w", n+2, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseCSVRow(row []string, index map[string]int) (Record, error) {
+	cell := func(name string) string {
+		if i, ok := index[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	number := func(name string) (float64, error) {
+		if cell(name) == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(cell(name), 64)
+	}
+
+	var record Record
+	if v := cell("schema_version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return record, err
+		}
+		if err := checkVersion(header{Version: version}); err != nil {
+			return record, err
+		}
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, cell("time"))
+	if err != nil {
+		return record, err
+	}
+	record = Record{
+		ID:     cell("id"),
+		Time:   at,
+		Mode:   cell("mode"),
+		Lang:   cell("lang"),
+		Layout: cell("layout"),
+		Level:  cell("level"),
+	}
+	if v := cell("word_count"); v != "" {
+		if record.WordCount, err = strconv.Atoi(v); err != nil {
+			return record, err
+		}
+	}
+	if v := cell("tags"); v != "" {
+		record.Tags = strings.Split(v, ",")
+	}
+
+	s := &record.Stats
+	seconds, err := number("duration_seconds")
+	if err != nil {
+		return record, err
+	}
+	s.Duration = time.Duration(seconds * float64(time.Second))
+	if s.WPM, err = number("wpm"); err != nil {
+		return record, err
+	}
+	if s.Accuracy, err = number("accuracy"); err != nil {
+		return record, err
+	}
+	s.Finished = cell("finished") != "false"
+	s.Retry = cell("retry") == "true"
+	if s.Difficulty, err = number("difficulty"); err != nil {
+		return record, err
+	}
+	s.Text, s.Input = cell("text"), cell("input")
+
+	for name, v := range map[string]any{
+		"mistyped": &s.Mistyped, "confusions": &s.Confusions, "errors": &s.Errors,
+		"words": &s.Words, "fingers": &s.Fingers, "keystrokes": &s.Keystrokes,
+	} {
+		if data := cell(name); data != "" && data != "null" {
+			if err := json.Unmarshal([]byte(data), v); err != nil {
+				return record, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return record, nil
+}
+
+// Merge adds records to the history, skipping any it already has. It
+// returns the records that were new.
+func Merge(records []Record) ([]Record, error) {
+	prepared := make([]Record, len(records))
+	for i, record := range records {
+		if record.ID == "" {
+			record.Time = record.Time.UTC()
+			record.ID = record.fingerprint()
+		}
+		tags, err := race.ParseTags(record.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("race of %s: %w", record.Time.Format(time.RFC3339), err)
+		}
+		record.Tags = tags
+		prepared[i] = record
+	}
+
+	// The totals are read first, so building them from the history the
+	// first time does not count the new races twice.
+	words, err := LoadWords(Load)
+	if err != nil {
+		return nil, err
+	}
+	added, err := appendNew(prepared)
+	if err != nil || len(added) == 0 {
+		return nil, err
+	}
+
+	for _, record := range added {
+		words.Add(record.Stats)
+	}
+	if err := words.Save(); err != nil {
+		return nil, err
+	}
+
+	for _, hook := range importHooks {
+		if err := hook(added); err != nil {
+			return nil, err
+		}
+	}
+	return added, nil
+}
+
+// importHooks let data kept alongside the history, such as personal bests,
+// take imported races into account. They are installed by main.
+var importHooks []func([]Record) error
+
+func AddImportHook(fn func([]Record) error) {
+	importHooks = append(importHooks, fn)
+}
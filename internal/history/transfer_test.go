@@ -0,0 +1,166 @@
+package history
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/race"
+)
+
+// sampleRecords sets every field of Record and Stats, so a round trip that
+// drops one fails.
+func sampleRecords() []Record {
+	full := Record{
+		ID:        "abc123",
+		Time:      time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC),
+		Mode:      "words",
+		Lang:      "de",
+		Layout:    "colemak",
+		WordCount: 25,
+		Level:     "hard",
+		Tags:      []string{"split", "evening"},
+		Stats: race.Stats{
+			Duration:   12500 * time.Millisecond,
+			Accuracy:   96.5,
+			WPM:        71.25,
+			Mistyped:   []race.MistypedChar{{Char: 'ß', Count: 2}},
+			Confusions: []race.Confusion{{Expected: 'ß', Typed: 's', Count: 2}},
+			Text:       "Straße, \"quoted\"\nline",
+			Input:      "Strase, \"quoted\"\nline",
+			Keystrokes: []race.Keystroke{
+				{Index: 0, Expected: 'S', Typed: 'S', At: 100 * time.Millisecond},
+				{Index: 1, At: 200 * time.Millisecond, Backspace: true},
+			},
+			Errors:     race.ErrorCounts{Substitutions: 1},
+			Words:      []race.WordStat{{Word: "Straße,", Start: 0, End: time.Second, WPM: 84, Errors: 1}},
+			Retry:      true,
+			Difficulty: 4.375,
+			Fingers: &race.FingerReport{
+				Fingers:     map[keyboard.Finger]race.KeyStat{keyboard.LeftIndex: {Presses: 3, Errors: 1, Latency: time.Second, Timed: 2}},
+				SameFinger:  race.KeyStat{Presses: 1},
+				Transitions: 4,
+			},
+			Finished: true,
+		},
+	}
+	bare := Record{
+		ID:   "def456",
+		Time: time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC),
+		Mode: Monkeytype,
+		Stats: race.Stats{
+			WPM:      50,
+			Accuracy: 90,
+		},
+	}
+	return []Record{full, bare}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			want := sampleRecords()
+			var buf bytes.Buffer
+			if err := Export(&buf, format, want); err != nil {
+				t.Fatal(err)
+			}
+			if detected := DetectFormat("export."+format, buf.Bytes()); detected != format {
+				t.Errorf("exported %s detected as %s", format, detected)
+			}
+			got, err := Parse(buf.Bytes(), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip changed the records:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseNewerSchema(t *testing.T) {
+	for format, data := range map[string]string{
+		"json":   `{"schema":"typ0-history","version":99,"records":[]}`,
+		"ndjson": `{"schema":"typ0-history","version":99}` + "\n",
+		"csv":    "schema_version,time,mode,wpm\n99,2024-01-01T00:00:00Z,words,50\n",
+	} {
+		if _, err := Parse([]byte(data), format); err == nil || !strings.Contains(err.Error(), "newer") {
+			t.Errorf("%s: error = %v, want one about a newer schema", format, err)
+		}
+	}
+}
+
+func TestParseCSVWithoutOptionalColumns(t *testing.T) {
+	records, err := Parse([]byte("time,mode,wpm\n2024-01-01T00:00:00Z,words,50\n"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Record{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Mode: "words"}
+	want.Stats.WPM = 50
+	want.Stats.Finished = true
+	if len(records) != 1 || !reflect.DeepEqual(records[0], want) {
+		t.Errorf("records = %+v, want [%+v]", records, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Setenv("TYP0_HOME", t.TempDir())
+	t.Setenv("TYP0_PROFILE", "")
+
+	at := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+	raced := func(minutes int, tags ...string) Record {
+		return Record{
+			Time: at.Add(time.Duration(minutes) * time.Minute),
+			Mode: Monkeytype,
+			Tags: tags,
+			Stats: race.Stats{
+				Text: "hello", Input: "hello", WPM: 50, Finished: true,
+				Words: []race.WordStat{{Word: "hello", End: time.Second, WPM: 60}},
+			},
+		}
+	}
+
+	added, err := Merge([]Record{raced(0, " Split", "split", "EVENING"), raced(1), raced(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("added %d races, want 2", len(added))
+	}
+	if want := []string{"split", "evening"}; !reflect.DeepEqual(added[0].Tags, want) {
+		t.Errorf("tags = %q, want %q", added[0].Tags, want)
+	}
+
+	added, err = Merge([]Record{raced(1), raced(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || !added[0].Time.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("second import added %+v, want only the race at 7:02", added)
+	}
+
+	records, err := Find(Query{Tag: "split"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("found %d races tagged split, want 1", len(records))
+	}
+	words, err := LoadWords(func() ([]Record, error) {
+		t.Fatal("word totals were not saved")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := words.Totals["hello"].Count; got != 3 {
+		t.Errorf("hello counted %d times, want once per new race, 3", got)
+	}
+
+	if _, err := Merge([]Record{raced(3, "a,b")}); err == nil {
+		t.Error("Merge accepted a tag with a comma")
+	}
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+)
+
+const wordsFile = "words.json"
+
+// Words keeps how every word has been typed across races, so problem words
+// are known without reading the whole history.
+type Words struct {
+	Totals map[string]race.WordTotal `json:"totals"`
+}
+
+// LoadWords reads the word totals. The first time, before any are saved,
+// it builds them from the races returned by records, which is not called
+// otherwise.
+func LoadWords(records func() ([]Record, error)) (*Words, error) {
+	var words Words
+	if err := storage.Load(wordsFile, &words); err != nil {
+		return nil, err
+	}
+	if words.Totals == nil {
+		all, err := records()
+		if err != nil {
+			return nil, err
+		}
+		words.Totals = make(map[string]race.WordTotal)
+		for _, record := range all {
+			words.Add(record.Stats)
+		}
+	}
+	return &words, nil
+}
+
+func (w *Words) Add(stats race.Stats) {
+	race.AddWords(w.Totals, stats)
+}
+
+func (w *Words) Save() error {
+	return storage.Save(wordsFile, w)
+}
+
+func (w *Words) Problems(n int) []string {
+	return race.ProblemWords(w.Totals, n)
+}
+
+// ProblemWords returns up to n of the saved problem words.
+func ProblemWords(n int) ([]string, error) {
+	words, err := LoadWords(Load)
+	if err != nil {
+		return nil, err
+	}
+	return words.Problems(n), nil
+}
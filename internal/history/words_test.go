@@ -0,0 +1,36 @@
+package history
+
+import (
+	"testing"
+
+	"go-typ0/internal/race"
+)
+
+func TestLoadWordsBuildsOnce(t *testing.T) {
+	t.Setenv("TYP0_HOME", t.TempDir())
+	t.Setenv("TYP0_PROFILE", "")
+
+	calls := 0
+	records := func() ([]Record, error) {
+		calls++
+		return []Record{{Stats: race.Stats{Text: "ab", Input: "ab"}}}, nil
+	}
+
+	words, err := LoadWords(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("records read %d times to build the totals, want 1", calls)
+	}
+	if err := words.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadWords(records); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("records read again although the totals were saved")
+	}
+}
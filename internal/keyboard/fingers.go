@@ -0,0 +1,198 @@
+package keyboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-typ0/internal/layout"
+)
+
+// A finger map file assigns a finger to every key of the four character
+// rows, in the same row order as a layout file, one digit per key:
+//
+//	0 left pinky   5 right thumb
+//	1 left ring    6 right index
+//	2 left middle  7 right middle
+//	3 left index   8 right ring
+//	4 left thumb   9 right pinky
+//
+// Blank lines and lines starting with '#' are ignored.
+
+type Finger int
+
+const (
+	LeftPinky Finger = iota
+	LeftRing
+	LeftMiddle
+	LeftIndex
+	LeftThumb
+	RightThumb
+	RightIndex
+	RightMiddle
+	RightRing
+	RightPinky
+)
+
+var fingerNames = []string{
+	"left pinky", "left ring", "left middle", "left index", "left thumb",
+	"right thumb", "right index", "right middle", "right ring", "right pinky",
+}
+
+func (f Finger) String() string {
+	if f < 0 || int(f) >= len(fingerNames) {
+		return "unknown"
+	}
+	return fingerNames[f]
+}
+
+func (f Finger) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *Finger) UnmarshalText(text []byte) error {
+	for i, name := range fingerNames {
+		if name == string(text) {
+			*f = Finger(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown finger %q", text)
+}
+
+type Hand int
+
+const (
+	Left Hand = iota
+	Right
+)
+
+func (h Hand) String() string {
+	if h == Left {
+		return "left"
+	}
+	return "right"
+}
+
+func (f Finger) Hand() Hand {
+	if f <= LeftThumb {
+		return Left
+	}
+	return Right
+}
+
+// Fingers lists every finger in keyboard order.
+func Fingers() []Finger {
+	fingers := make([]Finger, len(fingerNames))
+	for i := range fingers {
+		fingers[i] = Finger(i)
+	}
+	return fingers
+}
+
+type FingerMap struct {
+	Rows [layout.Rows][]Finger
+}
+
+const defaultFingers = `
+0012336678999
+0123366789999
+01233667899
+0123366789
+`
+
+var standardFingers = mustParseFingers(defaultFingers)
+
+// StandardFingers is the usual touch typing assignment.
+func StandardFingers() *FingerMap {
+	return standardFingers
+}
+
+func LoadFingers(path string) (*FingerMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseFingers(f)
+}
+
+func ParseFingers(r io.Reader) (*FingerMap, error) {
+	fm := &FingerMap{}
+	row := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if row == layout.Rows {
+			return nil, fmt.Errorf("finger map: more than %d rows", layout.Rows)
+		}
+		for _, c := range line {
+			if c < '0' || c > '9' {
+				return nil, fmt.Errorf("finger map row %d: %q is not a finger digit", row+1, c)
+			}
+			fm.Rows[row] = append(fm.Rows[row], Finger(c-'0'))
+		}
+		row++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if row != layout.Rows {
+		return nil, fmt.Errorf("finger map: expected %d rows, got %d", layout.Rows, row)
+	}
+	return fm, nil
+}
+
+func mustParseFingers(s string) *FingerMap {
+	fm, err := ParseFingers(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return fm
+}
+
+func (fm *FingerMap) At(row, col int) (Finger, bool) {
+	if row < 0 || row >= layout.Rows || col >= len(fm.Rows[row]) {
+		return 0, false
+	}
+	return fm.Rows[row][col], true
+}
+
+// Press describes how a character is typed: the key and finger, and for
+// shifted characters the Shift key on the other hand.
+type Press struct {
+	Key         rune
+	Finger      Finger
+	Shift       rune
+	ShiftFinger Finger
+}
+
+// Locate works out how r is typed on layout l with finger map fm.
+func Locate(r rune, l *layout.Layout, fm *FingerMap) (Press, bool) {
+	if r == ' ' {
+		return Press{Key: ' ', Finger: RightThumb}, true
+	}
+
+	row, col, shift, ok := l.Position(r)
+	if !ok {
+		return Press{}, false
+	}
+	finger, ok := fm.At(row, col)
+	if !ok {
+		return Press{}, false
+	}
+
+	press := Press{Key: l.Key(row, col, false), Finger: finger}
+	if shift {
+		press.Shift, press.ShiftFinger = ShiftRight, RightPinky
+		if finger.Hand() == Right {
+			press.Shift, press.ShiftFinger = ShiftLeft, LeftPinky
+		}
+	}
+	return press, true
+}
@@ -0,0 +1,44 @@
+package keyboard
+
+import (
+	"go-typ0/internal/layout"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fingerColors mirror each other across the hands so the same finger has
+// the same colour on both sides.
+var fingerColors = []lipgloss.Color{"5", "4", "6", "2", "8", "8", "2", "6", "4", "5"}
+
+func (f Finger) Color() lipgloss.Color {
+	return fingerColors[f]
+}
+
+// Guide returns a style function for Render that tints every key with the
+// colour of its finger and highlights the keys of press.
+func Guide(l *layout.Layout, fm *FingerMap, press Press) func(r rune) lipgloss.Style {
+	fingers := map[rune]Finger{
+		' ':        RightThumb,
+		ShiftLeft:  LeftPinky,
+		ShiftRight: RightPinky,
+	}
+	for row := 0; row < layout.Rows; row++ {
+		for col, key := range l.Keys[row] {
+			if finger, ok := fm.At(row, col); ok {
+				fingers[key] = finger
+			}
+		}
+	}
+
+	return func(r rune) lipgloss.Style {
+		finger, ok := fingers[r]
+		if !ok {
+			return noData
+		}
+		style := lipgloss.NewStyle().Foreground(finger.Color())
+		if r == press.Key || (press.Shift != 0 && r == press.Shift) {
+			style = style.Reverse(true).Bold(true)
+		}
+		return style
+	}
+}
@@ -0,0 +1,46 @@
+package keyboard
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatPalette runs from good (green) to bad (red).
+var heatPalette = []lipgloss.Color{"28", "64", "100", "136", "172", "166", "160", "124"}
+
+var noData = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+// Heat returns a style function for Render that colours each key by its
+// value relative to the largest one. Keys without a value are dimmed.
+func Heat(values map[rune]float64) func(r rune) lipgloss.Style {
+	highest := 0.0
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+
+	return func(r rune) lipgloss.Style {
+		v, ok := values[r]
+		if !ok {
+			return noData
+		}
+
+		i := 0
+		if highest > 0 {
+			i = int(v / highest * float64(len(heatPalette)-1))
+		}
+		return lipgloss.NewStyle().
+			Background(heatPalette[i]).
+			Foreground(lipgloss.Color("15"))
+	}
+}
+
+func Legend() string {
+	var b strings.Builder
+	b.WriteString("low ")
+	for _, color := range heatPalette {
+		b.WriteString(lipgloss.NewStyle().Foreground(color).Render("█"))
+	}
+	b.WriteString(" high")
+	return b.String()
+}
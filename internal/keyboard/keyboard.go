@@ -0,0 +1,167 @@
+package keyboard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-typ0/internal/layout"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyWidth is the width of a one unit key cell; keys are separated by a
+// single space.
+const keyWidth = 3
+
+// ShiftLeft and ShiftRight stand for the Shift keys, which type nothing.
+const (
+	ShiftLeft  rune = '\uE000'
+	ShiftRight rune = '\uE001'
+)
+
+// Key is a key on the physical keyboard. Keys with a Row of at least zero
+// show the character at that position of the logical layout; the others
+// show Label and stand for Char, if any.
+type Key struct {
+	Row, Col int
+	Char     rune
+	Label    string
+	Units    int
+}
+
+type Row struct {
+	Indent int
+	Keys   []Key
+}
+
+// Geometry is the physical arrangement of the character keys and the space
+// bar; modifiers are only hinted at by each row's indent.
+type Geometry struct {
+	Name string
+	Rows []Row
+}
+
+var geometries = map[string]*Geometry{
+	"ansi": {
+		Name: "ANSI",
+		Rows: []Row{
+			{Indent: 0, Keys: span(0, 0, 13)},
+			{Indent: 6, Keys: span(1, 0, 13)},
+			{Indent: 7, Keys: span(2, 0, 11)},
+			{Indent: 1, Keys: shifted(2, span(3, 0, 10), 2)},
+			{Indent: 16, Keys: []Key{spaceBar(6)}},
+		},
+	},
+	"iso": {
+		Name: "ISO",
+		Rows: []Row{
+			{Indent: 0, Keys: span(0, 0, 13)},
+			{Indent: 6, Keys: span(1, 0, 12)},
+			{Indent: 7, Keys: append(span(2, 0, 11), Key{Row: 1, Col: 12})},
+			{Indent: 1, Keys: shifted(1, append([]Key{{Row: -1, Label: "<"}}, span(3, 0, 10)...), 2)},
+			{Indent: 16, Keys: []Key{spaceBar(6)}},
+		},
+	},
+	"ortho": {
+		Name: "Ortholinear",
+		Rows: []Row{
+			{Indent: 0, Keys: span(0, 0, 12)},
+			{Indent: 4, Keys: span(1, 0, 11)},
+			{Indent: 4, Keys: span(2, 0, 11)},
+			{Indent: 0, Keys: shifted(1, span(3, 0, 10), 1)},
+			{Indent: 16, Keys: []Key{spaceBar(4)}},
+		},
+	},
+}
+
+func span(row, from, to int) []Key {
+	keys := make([]Key, 0, to-from)
+	for col := from; col < to; col++ {
+		keys = append(keys, Key{Row: row, Col: col})
+	}
+	return keys
+}
+
+// shifted surrounds the keys of the bottom row with Shift keys of the given
+// widths.
+func shifted(left int, keys []Key, right int) []Key {
+	row := []Key{shiftKey(ShiftLeft, left)}
+	row = append(row, keys...)
+	return append(row, shiftKey(ShiftRight, right))
+}
+
+func shiftKey(r rune, units int) Key {
+	label := "shift"
+	if units < 2 {
+		label = "⇧"
+	}
+	return Key{Row: -1, Char: r, Label: label, Units: units}
+}
+
+func spaceBar(units int) Key {
+	return Key{Row: -1, Char: ' ', Label: "space", Units: units}
+}
+
+// Lookup returns the geometry called name: ansi, iso or ortho.
+func Lookup(name string) (*Geometry, error) {
+	if name == "" {
+		name = "ansi"
+	}
+	if g, ok := geometries[strings.ToLower(name)]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("unknown keyboard %q (available: %s)", name, strings.Join(Names(), ", "))
+}
+
+func Names() []string {
+	var names []string
+	for name := range geometries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rune returns the unshifted character of key in l, or 0 for keys that
+// type nothing.
+func (k Key) Rune(l *layout.Layout) rune {
+	if k.Row < 0 {
+		return k.Char
+	}
+	return l.Key(k.Row, k.Col, false)
+}
+
+// Render draws the keyboard with the characters of l. style picks the
+// style of each key from its unshifted character; keys that type nothing
+// are passed 0.
+func (g *Geometry) Render(l *layout.Layout, style func(r rune) lipgloss.Style) string {
+	lines := make([]string, 0, len(g.Rows))
+	for _, row := range g.Rows {
+		var cells []string
+		for _, key := range row.Keys {
+			r := key.Rune(l)
+			if key.Row >= 0 && r == 0 {
+				continue
+			}
+
+			label := key.Label
+			if key.Row >= 0 {
+				label = string(r)
+			}
+			units := max(key.Units, 1)
+			width := units*keyWidth + units - 1
+			cells = append(cells, style(r).Render(center(label, width)))
+		}
+		lines = append(lines, strings.Repeat(" ", row.Indent)+strings.Join(cells, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func center(label string, width int) string {
+	pad := width - lipgloss.Width(label)
+	if pad <= 0 {
+		return label
+	}
+	return strings.Repeat(" ", pad/2) + label + strings.Repeat(" ", pad-pad/2)
+}
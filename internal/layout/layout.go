@@ -0,0 +1,219 @@
+package layout
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A layout file describes the characters on the four character rows of a
+// keyboard, from the number row down to the bottom row:
+//
+//	# name: Colemak
+//	`1234567890-=
+//	qwfpgjluy;[]\
+//	arstdhneio'
+//	zxcvbkm,./
+//	~!@#$%^&*()_+
+//	QWFPGJLUY:{}|
+//	ARSTDHNEIO"
+//	ZXCVBKM<>?
+//
+// The first four rows are the unshifted characters, written left to right
+// starting with the leftmost key of each row, and the optional last four
+// rows are the same keys with Shift held. When the shifted rows are left
+// out, letters are upper-cased and symbols take QWERTY's shifted symbol.
+// Lines starting with '#' are comments, except "# name: <display name>".
+
+const Rows = 4
+
+//go:embed layouts
+var embedded embed.FS
+
+type Layout struct {
+	Name  string
+	Keys  [Rows][]rune
+	Shift [Rows][]rune
+}
+
+type position struct {
+	row, col int
+	shift    bool
+}
+
+// qwerty is the physical layout keys are read from. It is set in init
+// because parsing other layouts refers back to it.
+var qwerty *Layout
+
+func init() {
+	qwerty = mustBuiltin("qwerty")
+}
+
+func QWERTY() *Layout {
+	return qwerty
+}
+
+// Load returns the built-in layout called name, or reads name as a layout
+// file when no built-in matches.
+func Load(name string) (*Layout, error) {
+	if data, err := embedded.ReadFile("layouts/" + strings.ToLower(name) + ".txt"); err == nil {
+		return Parse(strings.NewReader(string(data)), name)
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unknown layout %q (built-in: %s)", name, strings.Join(Builtin(), ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f, strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)))
+}
+
+func Builtin() []string {
+	entries, _ := fs.ReadDir(embedded, "layouts")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Parse(r io.Reader, name string) (*Layout, error) {
+	layout := &Layout{Name: name}
+
+	var rows [][]rune
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			if value, ok := strings.CutPrefix(comment, "name:"); ok {
+				layout.Name = strings.TrimSpace(value)
+			}
+			continue
+		}
+		rows = append(rows, []rune(strings.TrimSpace(line)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) != Rows && len(rows) != 2*Rows {
+		return nil, fmt.Errorf("layout %s: expected %d or %d rows, got %d", name, Rows, 2*Rows, len(rows))
+	}
+
+	for i := 0; i < Rows; i++ {
+		layout.Keys[i] = rows[i]
+		if len(rows) == 2*Rows {
+			if len(rows[Rows+i]) != len(rows[i]) {
+				return nil, fmt.Errorf("layout %s: shifted row %d has %d keys, unshifted row has %d", name, i+1, len(rows[Rows+i]), len(rows[i]))
+			}
+			layout.Shift[i] = rows[Rows+i]
+			continue
+		}
+		layout.Shift[i] = deriveShift(rows[i])
+	}
+	return layout, nil
+}
+
+func deriveShift(row []rune) []rune {
+	shifted := make([]rune, len(row))
+	for i, r := range row {
+		shifted[i] = unicode.ToUpper(r)
+		if qwerty != nil && !unicode.IsLetter(r) {
+			if pos, ok := qwerty.find(r); ok && !pos.shift {
+				shifted[i] = qwerty.Shift[pos.row][pos.col]
+			}
+		}
+	}
+	return shifted
+}
+
+func (l *Layout) find(r rune) (position, bool) {
+	for row := 0; row < Rows; row++ {
+		for col, key := range l.Keys[row] {
+			if key == r {
+				return position{row, col, false}, true
+			}
+		}
+		for col, key := range l.Shift[row] {
+			if key == r {
+				return position{row, col, true}, true
+			}
+		}
+	}
+	return position{}, false
+}
+
+func (l *Layout) at(pos position) (rune, bool) {
+	keys := l.Keys[pos.row]
+	if pos.shift {
+		keys = l.Shift[pos.row]
+	}
+	if pos.col >= len(keys) {
+		return 0, false
+	}
+	return keys[pos.col], true
+}
+
+// Position reports the row, column and shift state of the key producing r.
+func (l *Layout) Position(r rune) (row, col int, shift, ok bool) {
+	pos, ok := l.find(r)
+	return pos.row, pos.col, pos.shift, ok
+}
+
+// Key returns the character at row and col, or 0 when there is no key.
+func (l *Layout) Key(row, col int, shift bool) rune {
+	r, _ := l.at(position{row, col, shift})
+	return r
+}
+
+// Base returns the unshifted character of the key producing r.
+func (l *Layout) Base(r rune) (rune, bool) {
+	pos, ok := l.find(r)
+	if !ok {
+		return 0, false
+	}
+	return l.Key(pos.row, pos.col, false), true
+}
+
+// Remap translates r, typed on a physical QWERTY keyboard, into the
+// character the same key produces in l. Characters without a QWERTY key,
+// such as space, pass through unchanged.
+func (l *Layout) Remap(r rune) rune {
+	pos, ok := qwerty.find(r)
+	if !ok {
+		return r
+	}
+	if mapped, ok := l.at(pos); ok {
+		return mapped
+	}
+	return r
+}
+
+func mustBuiltin(name string) *Layout {
+	data, err := embedded.ReadFile("layouts/" + name + ".txt")
+	if err != nil {
+		panic(err)
+	}
+	layout, err := Parse(strings.NewReader(string(data)), name)
+	if err != nil {
+		panic(err)
+	}
+	return layout
+}
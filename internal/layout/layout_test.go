@@ -0,0 +1,107 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+// typeOn returns what typing text on a QWERTY keyboard produces when l is
+// emulated.
+func typeOn(l *Layout, text string) string {
+	var out []rune
+	for _, r := range text {
+		out = append(out, l.Remap(r))
+	}
+	return string(out)
+}
+
+func TestRemap(t *testing.T) {
+	colemak, err := Load("colemak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dvorak, err := Load("dvorak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		layout *Layout
+		typed  string
+		want   string
+	}{
+		{QWERTY(), "Hello, World!", "Hello, World!"},
+		{colemak, "asdf jkl;", "arst neio"},
+		{colemak, "Jkdd; Eg;sf!", "Nesso Fdort!"},
+		{dvorak, "qwerty", "',.pyf"},
+		{dvorak, "D;nnr", "Esbbp"},
+		{colemak, "\t\n", "\t\n"},
+	}
+	for _, tt := range tests {
+		if got := typeOn(tt.layout, tt.typed); got != tt.want {
+			t.Errorf("%s: typing %q gives %q, want %q", tt.layout.Name, tt.typed, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	colemak, err := Load("colemak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Finding the QWERTY key for every character of a Colemak text and
+	// typing those keys gives the text back.
+	text := "The quick brown fox: \"jumps\" over 12 lazy dogs?"
+	var keys []rune
+	for _, r := range text {
+		row, col, shift, ok := colemak.Position(r)
+		if !ok {
+			keys = append(keys, r)
+			continue
+		}
+		keys = append(keys, QWERTY().Key(row, col, shift))
+	}
+	if got := typeOn(colemak, string(keys)); got != text {
+		t.Errorf("round trip gives %q, want %q", got, text)
+	}
+}
+
+func TestParse(t *testing.T) {
+	unshifted := "`1234567890-=\nqwfpgjluy;[]\\\narstdhneio'\nzxcvbkm,./\n"
+	layout, err := Parse(strings.NewReader("# name: Mine\n# a comment\n\n"+unshifted), "mine.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout.Name != "Mine" {
+		t.Errorf("Name = %q, want Mine", layout.Name)
+	}
+	// Without shifted rows letters are upper-cased and symbols take
+	// QWERTY's shifted symbol.
+	for r, want := range map[rune]rune{'a': 'A', ';': ':', '\'': '"', '1': '!'} {
+		row, col, _, _ := layout.Position(r)
+		if got := layout.Key(row, col, true); got != want {
+			t.Errorf("shifted %c = %c, want %c", r, got, want)
+		}
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "expected 4 or 8 rows, got 0"},
+		{"too few rows", "abc\ndef\n", "expected 4 or 8 rows, got 2"},
+		{"five rows", unshifted + "ABC\n", "expected 4 or 8 rows, got 5"},
+		{"short shifted row", unshifted + "~!@\nQWFPGJLUY:{}|\nARSTDHNEIO\"\nZXCVBKM<>?\n", "shifted row 1 has 3 keys, unshifted row has 13"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.data), tt.name)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := Load("no-such-layout"); err == nil || !strings.Contains(err.Error(), "unknown layout") {
+		t.Errorf("Load of a missing layout: error = %v", err)
+	}
+}
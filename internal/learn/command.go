@@ -0,0 +1,135 @@
+package learn
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+func NewCommand() *cobra.Command {
+	var (
+		wordCount      int
+		targetWPM      float64
+		targetAccuracy float64
+		reset          bool
+		flags          race.Flags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "learn",
+		Short: "Learn touch typing one letter at a time",
+		Long: `Start a progressive touch typing course. Practice text is made of
+pseudo-words using only the letters unlocked so far, starting with the home
+row of the --layout typed on. A new letter unlocks once every unlocked letter
+meets the speed and accuracy targets. Progress is saved between sessions.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if targetWPM <= 0 {
+				return errors.New("--target-wpm must be above 0")
+			}
+			if targetAccuracy <= 0 || targetAccuracy > 100 {
+				return errors.New("--target-accuracy must be above 0 and at most 100")
+			}
+
+			var opts race.Options
+			if err := flags.Apply(&opts); err != nil {
+				return err
+			}
+			progress, err := LoadProgress(opts.Layout, targetWPM, targetAccuracy)
+			if err != nil {
+				return err
+			}
+			if reset {
+				progress.Reset()
+				if err := progress.Save(); err != nil {
+					return err
+				}
+			}
+
+			opts.Source = func() string {
+				return progress.Text(wordCount)
+			}
+
+			model := race.NewModel(opts)
+			viewModel := race.NewViewModel(model)
+			saveErr := race.Record(viewModel, "learn", opts)
+
+			var unlocked rune
+			viewModel.OnFinish(func(stats race.Stats) {
+				unlocked = 0
+				if progress.Record(stats) {
+					unlocked = progress.UnlockedLetters()[progress.Unlocked-1]
+				}
+				if err := progress.Save(); err != nil {
+					fmt.Fprintln(os.Stderr, "Error saving progress: ", err)
+				}
+			})
+
+			styles := ui.NewStyles()
+			viewModel.SetPanel(func() string {
+				panel := renderProgress(progress, styles)
+				if unlocked != 0 {
+					panel = styles.GreenStyle.Render(fmt.Sprintf("New letter unlocked: %c", unlocked)) + "\n" + panel
+				}
+				return panel
+			})
+
+			p := tea.NewProgram(viewModel)
+			if _, err := p.Run(); err != nil {
+				fmt.Println("Error running program: ", err)
+				os.Exit(1)
+			}
+			return saveErr()
+		},
+	}
+
+	cmd.Flags().IntVarP(&wordCount, "words", "w", 15, "Number of words per exercise")
+	cmd.Flags().Float64Var(&targetWPM, "target-wpm", 35, "Speed every letter must reach before the next unlocks")
+	cmd.Flags().Float64Var(&targetAccuracy, "target-accuracy", 95, "Accuracy every letter must reach before the next unlocks")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Forget all progress and start over")
+	flags.Register(cmd)
+
+	return cmd
+}
+
+// renderProgress draws one confidence bar per letter, with locked letters
+// dimmed.
+func renderProgress(progress *Progress, styles *ui.Styles) string {
+	var letters, levels []string
+	for i, letter := range progress.letters {
+		if i >= progress.Unlocked {
+			letters = append(letters, styles.DimStyle.Render(string(letter)))
+			levels = append(levels, styles.DimStyle.Render("·"))
+			continue
+		}
+
+		confidence := progress.Confidence(letter)
+		bar := string(bars[int(confidence*float64(len(bars)-1))])
+		style := styles.ValueStyle
+		if progress.met(letter) {
+			style = styles.GreenStyle
+		} else if letter == progress.Focus() {
+			style = styles.RedStyle
+		}
+		letters = append(letters, style.Render(string(letter)))
+		levels = append(levels, style.Render(bar))
+	}
+
+	summary := fmt.Sprintf("%s %s  %s %d/%d",
+		styles.LabelStyle.Render("Focus:"), styles.ValueStyle.Render(string(progress.Focus())),
+		styles.LabelStyle.Render("Unlocked:"), progress.Unlocked, len(progress.letters))
+	if next := progress.Next(); next != 0 {
+		summary += fmt.Sprintf("  %s %s", styles.LabelStyle.Render("Next:"), styles.DimStyle.Render(string(next)))
+	}
+	summary += fmt.Sprintf("  %s %.0f WPM, %.0f%%", styles.LabelStyle.Render("Target:"), progress.targetWPM, progress.targetAccuracy)
+
+	return strings.Join(letters, " ") + "\n" + strings.Join(levels, " ") + "\n" + summary
+}
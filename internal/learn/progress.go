@@ -0,0 +1,218 @@
+package learn
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"go-typ0/internal/drill"
+	"go-typ0/internal/layout"
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+)
+
+const progressFile = "learn.json"
+
+// frequency ranks letters by how common they are in English text, which
+// decides the order the letters off the home row unlock in.
+const frequency = "etaoinshrdlcumwfgypbvkjxqz"
+
+const (
+	// homeRow is the layout row the fingers rest on; its keys in columns
+	// 4 and 5 are reached with a stretch of the index fingers.
+	homeRow = 2
+	// initialLetters is the least number of letters unlocked at the start.
+	initialLetters = 7
+	minSamples     = 10
+	// smoothing is the weight a new race carries in a letter's running
+	// averages.
+	smoothing = 0.3
+)
+
+type LetterStat struct {
+	Samples  int     `json:"samples"`
+	Latency  float64 `json:"latency_ms"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Letters returns the order in which letters unlock on lay: the home row
+// keys under the resting fingers first, left to right, then the rest of
+// the layout's letters by frequency. A nil lay means QWERTY.
+func Letters(lay *layout.Layout) (letters []rune, home int) {
+	if lay == nil {
+		lay = layout.QWERTY()
+	}
+	var rest []rune
+	for row := 0; row < layout.Rows; row++ {
+		for col, key := range lay.Keys[row] {
+			key = unicode.ToLower(key)
+			if !unicode.IsLetter(key) || slices.Contains(letters, key) || slices.Contains(rest, key) {
+				continue
+			}
+			if row == homeRow && (col < 4 || col >= 6 && col < 10) {
+				letters = append(letters, key)
+			} else {
+				rest = append(rest, key)
+			}
+		}
+	}
+	slices.SortStableFunc(rest, func(a, b rune) int {
+		return rank(a) - rank(b)
+	})
+	return append(letters, rest...), len(letters)
+}
+
+// rank orders letters missing from frequency after all the others.
+func rank(r rune) int {
+	if i := strings.IndexRune(frequency, r); i >= 0 {
+		return i
+	}
+	return len(frequency)
+}
+
+type Progress struct {
+	Unlocked int                    `json:"unlocked"`
+	Stats    map[string]*LetterStat `json:"stats"`
+
+	letters        []rune
+	initial        int
+	targetWPM      float64
+	targetAccuracy float64
+}
+
+func newProgress(lay *layout.Layout, targetWPM, targetAccuracy float64) *Progress {
+	letters, home := Letters(lay)
+	initial := min(len(letters), max(initialLetters, home))
+	return &Progress{
+		Unlocked:       initial,
+		Stats:          make(map[string]*LetterStat),
+		letters:        letters,
+		initial:        initial,
+		targetWPM:      targetWPM,
+		targetAccuracy: targetAccuracy,
+	}
+}
+
+// LoadProgress reads the saved progress for a course on lay.
+func LoadProgress(lay *layout.Layout, targetWPM, targetAccuracy float64) (*Progress, error) {
+	p := newProgress(lay, targetWPM, targetAccuracy)
+	if err := storage.Load(progressFile, p); err != nil {
+		return nil, err
+	}
+	if p.Stats == nil {
+		p.Stats = make(map[string]*LetterStat)
+	}
+	p.Unlocked = max(p.initial, min(p.Unlocked, len(p.letters)))
+	return p, nil
+}
+
+func (p *Progress) Save() error {
+	return storage.Save(progressFile, p)
+}
+
+func (p *Progress) Reset() {
+	p.Unlocked = p.initial
+	p.Stats = make(map[string]*LetterStat)
+}
+
+func (p *Progress) UnlockedLetters() []rune {
+	return slices.Clone(p.letters[:p.Unlocked])
+}
+
+// Next returns the letter that unlocks next, or 0 once all are unlocked.
+func (p *Progress) Next() rune {
+	if p.Unlocked >= len(p.letters) {
+		return 0
+	}
+	return p.letters[p.Unlocked]
+}
+
+// targetLatency is the time per keystroke that corresponds to the target
+// WPM, using the usual five characters per word.
+func (p *Progress) targetLatency() float64 {
+	return 60000 / (p.targetWPM * 5)
+}
+
+// Confidence scores a letter between 0 and 1 by how close it is to both the
+// speed and the accuracy target.
+func (p *Progress) Confidence(letter rune) float64 {
+	stat := p.Stats[string(letter)]
+	if stat == nil || stat.Samples == 0 || stat.Latency == 0 {
+		return 0
+	}
+
+	speed := math.Min(1, p.targetLatency()/stat.Latency)
+	accuracy := math.Min(1, stat.Accuracy/p.targetAccuracy)
+	confidence := math.Min(speed, accuracy)
+	if stat.Samples < minSamples {
+		confidence *= float64(stat.Samples) / minSamples
+	}
+	return confidence
+}
+
+func (p *Progress) met(letter rune) bool {
+	stat := p.Stats[string(letter)]
+	return stat != nil && stat.Samples >= minSamples && p.Confidence(letter) >= 1
+}
+
+// Focus returns the unlocked letter with the lowest confidence.
+func (p *Progress) Focus() rune {
+	letters := p.UnlockedLetters()
+	focus := letters[len(letters)-1]
+	lowest := p.Confidence(focus)
+	for _, letter := range letters {
+		if c := p.Confidence(letter); c < lowest {
+			focus, lowest = letter, c
+		}
+	}
+	return focus
+}
+
+// Record folds a finished race into the letter statistics and unlocks the
+// next letter when every unlocked letter meets the targets. It reports
+// whether a letter was unlocked.
+func (p *Progress) Record(stats race.Stats) bool {
+	for letter, key := range stats.KeyStats() {
+		stat := p.Stats[string(letter)]
+		if stat == nil {
+			stat = &LetterStat{}
+			p.Stats[string(letter)] = stat
+		}
+
+		accuracy := (1 - key.ErrorRate()) * 100
+		stat.Accuracy = blend(stat.Accuracy, accuracy, stat.Samples)
+		if key.Timed > 0 {
+			latency := float64(key.AverageLatency().Microseconds()) / 1000
+			stat.Latency = blend(stat.Latency, latency, stat.Samples)
+		}
+		stat.Samples += key.Presses
+	}
+
+	if p.Next() == 0 {
+		return false
+	}
+	for _, letter := range p.UnlockedLetters() {
+		if !p.met(letter) {
+			return false
+		}
+	}
+	p.Unlocked++
+	return true
+}
+
+func blend(old, value float64, samples int) float64 {
+	if samples == 0 || old == 0 {
+		return value
+	}
+	return old*(1-smoothing) + value*smoothing
+}
+
+// Text generates pseudo-words from the unlocked letters, weighted towards
+// the current focus letter.
+func (p *Progress) Text(count int) string {
+	keys := p.UnlockedLetters()
+	focus := p.Focus()
+	keys = append(keys, focus, focus)
+	return drill.Generate(nil, drill.Target{Keys: keys}, count, true)
+}
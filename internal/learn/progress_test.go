@@ -0,0 +1,123 @@
+package learn
+
+import (
+	"math"
+	"testing"
+
+	"go-typ0/internal/layout"
+	"go-typ0/internal/race"
+)
+
+func TestConfidence(t *testing.T) {
+	// 35 WPM is 60000 / (35 * 5) ms per keystroke.
+	target := 60000 / (35.0 * 5)
+
+	tests := []struct {
+		name string
+		stat *LetterStat
+		want float64
+	}{
+		{"no samples", nil, 0},
+		{"on target", &LetterStat{Samples: 20, Latency: target, Accuracy: 95}, 1},
+		{"faster and more accurate", &LetterStat{Samples: 20, Latency: target / 2, Accuracy: 100}, 1},
+		{"half speed", &LetterStat{Samples: 20, Latency: target * 2, Accuracy: 100}, 0.5},
+		{"inaccurate", &LetterStat{Samples: 20, Latency: target, Accuracy: 76}, 0.8},
+		{"few samples", &LetterStat{Samples: 5, Latency: target, Accuracy: 95}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProgress(nil, 35, 95)
+			if tt.stat != nil {
+				p.Stats["a"] = tt.stat
+			}
+			if got := p.Confidence('a'); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Confidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordUnlocks(t *testing.T) {
+	target := 60000 / (35.0 * 5)
+	p := newProgress(nil, 35, 95)
+	for _, letter := range p.UnlockedLetters() {
+		p.Stats[string(letter)] = &LetterStat{Samples: minSamples, Latency: target, Accuracy: 100}
+	}
+	p.Stats["a"].Accuracy = 90
+
+	if p.met('a') {
+		t.Fatal("letter below the accuracy target counts as met")
+	}
+	if p.Focus() != 'a' {
+		t.Errorf("Focus = %c, want a", p.Focus())
+	}
+
+	p.Stats["a"].Accuracy = 100
+	next := p.Next()
+	if !p.Record(race.Stats{}) {
+		t.Fatal("Record did not unlock a letter with every target met")
+	}
+	if p.Unlocked != initialLetters+1 || p.UnlockedLetters()[initialLetters] != next {
+		t.Errorf("unlocked %d letters, want %d ending in %c", p.Unlocked, initialLetters+1, next)
+	}
+}
+
+func TestLetters(t *testing.T) {
+	tests := []struct {
+		layout  string
+		letters string
+		start   int
+	}{
+		{"qwerty", "asdfjkletoinhrcumwgypbvxqz", 7},
+		{"colemak", "arstneiohdlcumwfgypbvkjxqz", 8},
+		{"dvorak", "aoeuhtnsirdlcmwfgypbvkjxqz", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			lay, err := layout.Load(tt.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			letters, _ := Letters(lay)
+			if string(letters) != tt.letters {
+				t.Errorf("Letters = %s, want %s", string(letters), tt.letters)
+			}
+
+			p := newProgress(lay, 35, 95)
+			if p.Unlocked != tt.start || string(p.UnlockedLetters()) != tt.letters[:tt.start] {
+				t.Errorf("course starts with %s, want %s", string(p.UnlockedLetters()), tt.letters[:tt.start])
+			}
+			if p.Next() != rune(tt.letters[tt.start]) {
+				t.Errorf("Next = %c, want %c", p.Next(), tt.letters[tt.start])
+			}
+		})
+	}
+
+	if letters, _ := Letters(nil); string(letters) != tests[0].letters {
+		t.Errorf("Letters(nil) = %s, want QWERTY's order", string(letters))
+	}
+}
+
+func TestLoadProgressClampsUnlocked(t *testing.T) {
+	t.Setenv("TYP0_HOME", t.TempDir())
+	t.Setenv("TYP0_PROFILE", "")
+	colemak, err := layout.Load("colemak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for saved, want := range map[int]int{0: 8, 12: 12, 99: 26} {
+		p := newProgress(colemak, 35, 95)
+		p.Unlocked = saved
+		if err := p.Save(); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadProgress(colemak, 35, 95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Unlocked != want {
+			t.Errorf("saved %d unlocked, loaded %d, want %d", saved, loaded.Unlocked, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package lesson
+
+import (
+	"fmt"
+	"os"
+
+	"go-typ0/internal/race"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lesson",
+		Short: "Run or check lesson files",
+		Long: `Lessons are YAML or JSON files describing ordered exercises, each with
+instructions, text to type (or settings to generate it) and pass criteria.`,
+	}
+
+	cmd.AddCommand(newRunCommand(), newValidateCommand())
+	return cmd
+}
+
+func newRunCommand() *cobra.Command {
+	var (
+		restart bool
+		flags   race.Flags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <file>",
+		Short: "Work through a lesson",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			lesson, err := Load(path)
+			if err != nil {
+				return err
+			}
+			if problems := lesson.Validate(); len(problems) > 0 {
+				return fmt.Errorf("%s is not a valid lesson: %w (run \"typ0 lesson validate\" for details)", path, problems[0])
+			}
+
+			progress, err := LoadProgress()
+			if err != nil {
+				return err
+			}
+			completion := progress.For(path, lesson)
+
+			start := completion.Resume(len(lesson.Exercises))
+			if restart {
+				start = 0
+			}
+
+			runner := NewRunner(lesson, completion, start, progress.Save, &flags)
+			p := tea.NewProgram(runner)
+			if _, err := p.Run(); err != nil {
+				fmt.Println("Error running program: ", err)
+				os.Exit(1)
+			}
+			return runner.Err()
+		},
+	}
+
+	cmd.Flags().BoolVar(&restart, "restart", false, "Start from the first exercise instead of the first one not yet passed")
+	flags.Register(cmd)
+	return cmd
+}
+
+func newValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <file>...",
+		Short: "Check lesson files for mistakes",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invalid := 0
+			for _, path := range args {
+				lesson, err := Load(path)
+				if err != nil {
+					fmt.Println(err)
+					invalid++
+					continue
+				}
+
+				problems := lesson.Validate()
+				if len(problems) == 0 {
+					fmt.Printf("%s: ok (%d exercises)\n", path, len(lesson.Exercises))
+					continue
+				}
+				invalid++
+				for _, problem := range problems {
+					fmt.Printf("%s: %v\n", path, problem)
+				}
+			}
+
+			if invalid > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d lesson files are invalid", invalid, len(args))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package lesson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-typ0/internal/drill"
+	"go-typ0/internal/race"
+	"go-typ0/internal/words"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lesson is an ordered list of exercises read from a YAML or JSON file.
+type Lesson struct {
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description,omitempty"`
+	Exercises   []Exercise `yaml:"exercises"`
+}
+
+// Exercise is typed either from fixed Text or from text produced by
+// Generate. Instructions are shown before the exercise starts.
+type Exercise struct {
+	Title        string    `yaml:"title,omitempty"`
+	Instructions string    `yaml:"instructions,omitempty"`
+	Text         string    `yaml:"text,omitempty"`
+	Generate     *Generate `yaml:"generate,omitempty"`
+	Pass         Criteria  `yaml:"pass,omitempty"`
+}
+
+// Generate mirrors the race and drill flags. With Keys or Bigrams set the
+// text is a drill, otherwise random words or a quote from the pack.
+type Generate struct {
+	Words   int      `yaml:"words,omitempty"`
+	Lang    string   `yaml:"lang,omitempty"`
+	Quote   bool     `yaml:"quote,omitempty"`
+	Keys    string   `yaml:"keys,omitempty"`
+	Bigrams []string `yaml:"bigrams,omitempty"`
+	Only    bool     `yaml:"only,omitempty"`
+}
+
+type Criteria struct {
+	WPM      float64 `yaml:"wpm,omitempty"`
+	Accuracy float64 `yaml:"accuracy,omitempty"`
+}
+
+func (c Criteria) Met(wpm, accuracy float64) bool {
+	return wpm >= c.WPM && accuracy >= c.Accuracy
+}
+
+func (c Criteria) String() string {
+	var parts []string
+	if c.WPM > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f WPM", c.WPM))
+	}
+	if c.Accuracy > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f%% accuracy", c.Accuracy))
+	}
+	if len(parts) == 0 {
+		return "finish the exercise"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (e Exercise) Name(i int) string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return fmt.Sprintf("Exercise %d", i+1)
+}
+
+// Load reads and decodes a lesson file. Unknown fields are rejected so
+// typos do not silently change an exercise.
+func Load(path string) (*Lesson, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var lesson Lesson
+	if err := decoder.Decode(&lesson); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: empty lesson file", path)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &lesson, nil
+}
+
+// Validate returns every problem found in the lesson.
+func (l *Lesson) Validate() []error {
+	var problems []error
+	if strings.TrimSpace(l.Title) == "" {
+		problems = append(problems, errors.New("lesson has no title"))
+	}
+	if len(l.Exercises) == 0 {
+		problems = append(problems, errors.New("lesson has no exercises"))
+	}
+
+	// Titles name exercises in messages and on screen, so they must not
+	// repeat.
+	titles := make(map[string]int)
+	for i, exercise := range l.Exercises {
+		if first, ok := titles[exercise.Title]; ok && exercise.Title != "" {
+			problems = append(problems, fmt.Errorf("%s: title is already used by exercise %d", exercise.Name(i), first+1))
+		} else {
+			titles[exercise.Title] = i
+		}
+		for _, err := range exercise.validate() {
+			problems = append(problems, fmt.Errorf("%s: %w", exercise.Name(i), err))
+		}
+	}
+	return problems
+}
+
+func (e Exercise) validate() []error {
+	var problems []error
+
+	switch {
+	case e.Text == "" && e.Generate == nil:
+		problems = append(problems, errors.New("needs either text or generate"))
+	case e.Text != "" && e.Generate != nil:
+		problems = append(problems, errors.New("has both text and generate"))
+	case e.Text != "" && strings.TrimSpace(e.Text) == "":
+		problems = append(problems, errors.New("text is blank"))
+	}
+
+	if g := e.Generate; g != nil {
+		if g.Words < 0 {
+			problems = append(problems, fmt.Errorf("generate.words must be positive, got %d", g.Words))
+		}
+		if g.Quote && (g.Keys != "" || len(g.Bigrams) > 0) {
+			problems = append(problems, errors.New("generate.quote cannot be combined with keys or bigrams"))
+		}
+		if g.Only && g.Keys == "" && len(g.Bigrams) == 0 {
+			problems = append(problems, errors.New("generate.only needs keys or bigrams"))
+		}
+		if g.Lang != "" {
+			if _, err := words.Load(g.Lang, words.UserDirs()...); err != nil {
+				problems = append(problems, fmt.Errorf("generate.lang: %w", err))
+			}
+		}
+	}
+
+	if e.Pass.WPM < 0 {
+		problems = append(problems, fmt.Errorf("pass.wpm must not be negative, got %g", e.Pass.WPM))
+	}
+	if e.Pass.Accuracy < 0 || e.Pass.Accuracy > 100 {
+		problems = append(problems, fmt.Errorf("pass.accuracy must be between 0 and 100, got %g", e.Pass.Accuracy))
+	}
+	return problems
+}
+
+// Options returns the race options that produce the exercise's text.
+func (e Exercise) Options() (race.Options, error) {
+	if e.Generate == nil {
+		text := e.Text
+		return race.Options{Source: func() string { return text }}, nil
+	}
+
+	g := e.Generate
+	pack, err := words.Resolve(g.Lang, "")
+	if err != nil {
+		return race.Options{}, err
+	}
+
+	opts := race.Options{WordCount: g.Words, Pack: pack, Quote: g.Quote}
+	if target := drill.NewTarget(g.Keys, g.Bigrams); !target.Empty() {
+		opts.Source = func() string {
+			return drill.Generate(pack.Words, target, g.Words, g.Only)
+		}
+	}
+	return opts, nil
+}
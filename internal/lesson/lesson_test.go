@@ -0,0 +1,95 @@
+package lesson
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Exercise{Title: "Home row", Text: "asdf jkl"}
+
+	tests := []struct {
+		name   string
+		lesson Lesson
+		want   []string
+	}{
+		{"valid", Lesson{Title: "Basics", Exercises: []Exercise{valid, {Generate: &Generate{Keys: "asdf", Only: true}}}}, nil},
+		{"no title", Lesson{Exercises: []Exercise{valid}}, []string{"lesson has no title"}},
+		{"no exercises", Lesson{Title: "Basics"}, []string{"lesson has no exercises"}},
+		{"missing text", Lesson{Title: "Basics", Exercises: []Exercise{{}}}, []string{"Exercise 1: needs either text or generate"}},
+		{"blank text", Lesson{Title: "Basics", Exercises: []Exercise{{Text: "  \n"}}}, []string{"Exercise 1: text is blank"}},
+		{
+			"text and generate",
+			Lesson{Title: "Basics", Exercises: []Exercise{{Text: "a", Generate: &Generate{}}}},
+			[]string{"Exercise 1: has both text and generate"},
+		},
+		{
+			"bad generate",
+			Lesson{Title: "Basics", Exercises: []Exercise{{Generate: &Generate{Words: -1, Quote: true, Keys: "a"}}, {Generate: &Generate{Only: true}}}},
+			[]string{
+				"Exercise 1: generate.words must be positive, got -1",
+				"Exercise 1: generate.quote cannot be combined with keys or bigrams",
+				"Exercise 2: generate.only needs keys or bigrams",
+			},
+		},
+		{
+			"negative wpm",
+			Lesson{Title: "Basics", Exercises: []Exercise{{Text: "a", Pass: Criteria{WPM: -5}}}},
+			[]string{"Exercise 1: pass.wpm must not be negative, got -5"},
+		},
+		{
+			"accuracy out of range",
+			Lesson{Title: "Basics", Exercises: []Exercise{{Text: "a", Pass: Criteria{Accuracy: 101}}, {Text: "b", Pass: Criteria{Accuracy: -1}}}},
+			[]string{
+				"Exercise 1: pass.accuracy must be between 0 and 100, got 101",
+				"Exercise 2: pass.accuracy must be between 0 and 100, got -1",
+			},
+		},
+		{
+			"duplicate titles",
+			Lesson{Title: "Basics", Exercises: []Exercise{valid, {Text: "b"}, valid, {Text: "c"}}},
+			[]string{"Home row: title is already used by exercise 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range tt.lesson.Validate() {
+				got = append(got, err.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	lesson, err := Load(write("ok.yaml", "title: Basics\nexercises:\n  - text: asdf\n    pass:\n      wpm: 20\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lesson.Title != "Basics" || len(lesson.Exercises) != 1 || lesson.Exercises[0].Pass.WPM != 20 {
+		t.Errorf("Load = %+v", lesson)
+	}
+
+	for name, data := range map[string]string{
+		"empty.yaml": "",
+		"typo.yaml":  "title: Basics\nexercises:\n  - txt: asdf\n",
+	} {
+		if _, err := Load(write(name, data)); err == nil {
+			t.Errorf("%s: Load succeeded, want an error", name)
+		}
+	}
+}
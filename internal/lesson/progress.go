@@ -0,0 +1,97 @@
+package lesson
+
+import (
+	"path/filepath"
+	"time"
+
+	"go-typ0/internal/storage"
+)
+
+const progressFile = "lessons.json"
+
+type Result struct {
+	Attempts     int       `json:"attempts"`
+	Passed       bool      `json:"passed"`
+	PassedAt     time.Time `json:"passed_at,omitempty"`
+	BestWPM      float64   `json:"best_wpm"`
+	BestAccuracy float64   `json:"best_accuracy"`
+}
+
+type Completion struct {
+	Title     string          `json:"title"`
+	Exercises map[int]*Result `json:"exercises"`
+}
+
+// Progress records lesson completion, keyed by the absolute path of the
+// lesson file.
+type Progress map[string]*Completion
+
+func LoadProgress() (Progress, error) {
+	progress := make(Progress)
+	if err := storage.Load(progressFile, &progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func (p Progress) Save() error {
+	return storage.Save(progressFile, p)
+}
+
+func (p Progress) For(path string, lesson *Lesson) *Completion {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	completion := p[path]
+	if completion == nil {
+		completion = &Completion{}
+		p[path] = completion
+	}
+	if completion.Exercises == nil {
+		completion.Exercises = make(map[int]*Result)
+	}
+	completion.Title = lesson.Title
+	return completion
+}
+
+func (c *Completion) Record(i int, wpm, accuracy float64, passed bool) {
+	result := c.Exercises[i]
+	if result == nil {
+		result = &Result{}
+		c.Exercises[i] = result
+	}
+
+	result.Attempts++
+	result.BestWPM = max(result.BestWPM, wpm)
+	result.BestAccuracy = max(result.BestAccuracy, accuracy)
+	if passed && !result.Passed {
+		result.Passed = true
+		result.PassedAt = time.Now()
+	}
+}
+
+func (c *Completion) Passed(i int) bool {
+	result := c.Exercises[i]
+	return result != nil && result.Passed
+}
+
+// Resume returns the first exercise not yet passed, or 0 when all are.
+func (c *Completion) Resume(total int) int {
+	for i := 0; i < total; i++ {
+		if !c.Passed(i) {
+			return i
+		}
+	}
+	return 0
+}
+
+func (c *Completion) PassedCount(total int) int {
+	count := 0
+	for i := 0; i < total; i++ {
+		if c.Passed(i) {
+			count++
+		}
+	}
+	return count
+}
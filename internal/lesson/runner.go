@@ -0,0 +1,221 @@
+package lesson
+
+import (
+	"fmt"
+	"strings"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type phase int
+
+const (
+	phaseIntro phase = iota
+	phaseRace
+	phaseDone
+)
+
+// Runner walks through a lesson, showing each exercise's instructions and
+// then racing it until the pass criteria are met.
+type Runner struct {
+	lesson     *Lesson
+	completion *Completion
+	save       func() error
+	flags      *race.Flags
+	saveErrs   []func() error
+
+	current int
+	phase   phase
+	model   *race.Model
+	race    *race.ViewModel
+	passed  bool
+	err     error
+
+	width  int
+	height int
+	styles *ui.Styles
+}
+
+func NewRunner(lesson *Lesson, completion *Completion, start int, save func() error, flags *race.Flags) *Runner {
+	return &Runner{
+		lesson:     lesson,
+		completion: completion,
+		save:       save,
+		flags:      flags,
+		current:    start,
+		styles:     ui.NewStyles(),
+	}
+}
+
+func (r *Runner) Init() tea.Cmd {
+	return tea.EnterAltScreen
+}
+
+func (r *Runner) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.width, r.height = msg.Width, msg.Height
+		if r.race != nil {
+			r.race.Update(msg)
+		}
+		return r, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return r, tea.Quit
+		}
+	}
+
+	switch r.phase {
+	case phaseIntro:
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.Type {
+			case tea.KeyEsc:
+				return r, tea.Quit
+			case tea.KeyEnter:
+				return r, r.start()
+			}
+		}
+	case phaseRace:
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter && r.model.Finished() && r.passed {
+			r.next()
+			return r, nil
+		}
+		_, cmd := r.race.Update(msg)
+		return r, cmd
+	case phaseDone:
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return r, tea.Quit
+		}
+	}
+	return r, nil
+}
+
+func (r *Runner) start() tea.Cmd {
+	exercise := r.lesson.Exercises[r.current]
+	opts, err := exercise.Options()
+	if err == nil {
+		err = r.flags.Apply(&opts)
+	}
+	if err != nil {
+		r.err = err
+		return tea.Quit
+	}
+
+	r.passed = false
+	r.model = race.NewModel(opts)
+	r.race = race.NewViewModel(r.model)
+	r.race.DisableRetry()
+	r.race.OnFinish(func(stats race.Stats) {
+		r.passed = exercise.Pass.Met(stats.WPM, stats.Accuracy)
+		r.completion.Record(r.current, stats.WPM, stats.Accuracy, r.passed)
+		if err := r.save(); err != nil {
+			r.err = err
+		}
+	})
+	r.race.SetPanel(r.renderPanel)
+	r.saveErrs = append(r.saveErrs, race.Record(r.race, "lesson", opts))
+	if r.width > 0 {
+		r.race.Update(tea.WindowSizeMsg{Width: r.width, Height: r.height})
+	}
+
+	r.phase = phaseRace
+	return r.race.Init()
+}
+
+func (r *Runner) next() {
+	r.race, r.model = nil, nil
+	r.current++
+	if r.current >= len(r.lesson.Exercises) {
+		r.phase = phaseDone
+		return
+	}
+	r.phase = phaseIntro
+}
+
+// Err returns the error that ended the lesson early or the first problem
+// saving the history, if any.
+func (r *Runner) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, saveErr := range r.saveErrs {
+		if err := saveErr(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) View() string {
+	var content string
+	switch r.phase {
+	case phaseRace:
+		return r.race.View()
+	case phaseIntro:
+		content = r.renderIntro()
+	case phaseDone:
+		content = r.renderDone()
+	}
+
+	if r.width > 0 && r.height > 0 {
+		return lipgloss.Place(r.width, r.height, lipgloss.Center, lipgloss.Center, content)
+	}
+	return content
+}
+
+func (r *Runner) renderIntro() string {
+	exercise := r.lesson.Exercises[r.current]
+	lines := []string{
+		r.styles.ValueStyle.Render(r.lesson.Title),
+		r.styles.LabelStyle.Render(fmt.Sprintf("%d/%d  %s", r.current+1, len(r.lesson.Exercises), exercise.Name(r.current))),
+	}
+	if r.current == 0 && r.lesson.Description != "" {
+		lines = append(lines, "", strings.TrimSpace(r.lesson.Description))
+	}
+	if exercise.Instructions != "" {
+		lines = append(lines, "", strings.TrimSpace(exercise.Instructions))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%s %s", r.styles.LabelStyle.Render("To pass:"), exercise.Pass),
+		"",
+		r.styles.LabelStyle.Render("Press Enter to start. ESC/CTRL+C to quit"),
+	)
+	return r.styles.StatsBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (r *Runner) renderPanel() string {
+	exercise := r.lesson.Exercises[r.current]
+	status := fmt.Sprintf("%s %d/%d  %s %s",
+		r.styles.LabelStyle.Render("Exercise"), r.current+1, len(r.lesson.Exercises),
+		r.styles.LabelStyle.Render("To pass:"), exercise.Pass)
+
+	if !r.model.Finished() {
+		return status
+	}
+	if r.passed {
+		return status + "\n" + r.styles.GreenStyle.Render("Passed! Press Enter for the next exercise")
+	}
+	return status + "\n" + r.styles.RedStyle.Render("Not passed yet. Press Enter to try again")
+}
+
+func (r *Runner) renderDone() string {
+	total := len(r.lesson.Exercises)
+	lines := []string{
+		r.styles.ValueStyle.Render(r.lesson.Title),
+		"",
+		fmt.Sprintf("%s %d/%d exercises passed", r.styles.LabelStyle.Render("Complete:"), r.completion.PassedCount(total), total),
+	}
+	for i, exercise := range r.lesson.Exercises {
+		if result := r.completion.Exercises[i]; result != nil {
+			lines = append(lines, fmt.Sprintf("- %s %s", exercise.Name(i),
+				r.styles.ValueStyle.Render(fmt.Sprintf("%.0f WPM, %.0f%%", result.BestWPM, result.BestAccuracy))))
+		}
+	}
+	lines = append(lines, "", r.styles.LabelStyle.Render("Press any key to quit"))
+	return r.styles.StatsBoxStyle.Render(strings.Join(lines, "\n"))
+}
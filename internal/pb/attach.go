@@ -0,0 +1,44 @@
+package pb
+
+import (
+	"fmt"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+	"go-typ0/internal/ui"
+)
+
+// Attach keeps the personal bests up to date as vm finishes races and
+// announces new ones on the results screen. It is a race.RecorderFunc.
+func Attach(vm *race.ViewModel, mode string, opts race.Options) func() error {
+	key := KeyFor(history.NewMeta(mode, opts))
+	bests, err := Load()
+	styles := ui.NewStyles()
+
+	var notice string
+	vm.OnFinish(func(stats race.Stats) {
+		notice = ""
+		if bests == nil {
+			return
+		}
+
+		previous, beaten := bests.Update(key, stats, time.Now())
+		switch {
+		case beaten && previous != nil:
+			notice = styles.GreenStyle.Bold(true).Render(fmt.Sprintf("★ New personal best! %.2f WPM (+%.2f)", stats.WPM, stats.WPM-previous.WPM))
+		case beaten:
+			notice = styles.GreenStyle.Render(fmt.Sprintf("★ First personal best for %s", key))
+		case previous != nil:
+			notice = styles.LabelStyle.Render(fmt.Sprintf("Personal best: %.2f WPM (%+.2f)", previous.WPM, stats.WPM-previous.WPM))
+		}
+
+		if beaten {
+			if saveErr := bests.Save(); saveErr != nil {
+				err = saveErr
+			}
+		}
+	})
+	vm.AddNotice(func() string { return notice })
+	return func() error { return err }
+}
@@ -0,0 +1,59 @@
+package pb
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	var mode string
+
+	cmd := &cobra.Command{
+		Use:   "pb",
+		Short: "List your personal bests",
+		Long: `List the best WPM for every combination of mode, word count, language and
+layout you have raced. Races ended early and follow-up retries do not count.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bests, err := Load()
+			if err != nil {
+				return err
+			}
+
+			all := bests.All()
+			shown := 0
+			for _, best := range all {
+				if mode != "" && best.Key.Mode != mode {
+					continue
+				}
+				if shown == 0 {
+					fmt.Printf("%-8s %-6s %-5s %-10s %-6s %8s %9s  %s\n", "MODE", "WORDS", "LANG", "LAYOUT", "LEVEL", "WPM", "ACCURACY", "DATE")
+				}
+				shown++
+
+				words := "-"
+				if best.Key.WordCount > 0 {
+					words = fmt.Sprint(best.Key.WordCount)
+				}
+				fmt.Printf("%-8s %-6s %-5s %-10s %-6s %8.2f %8.2f%%  %s\n",
+					best.Key.Mode, words, dash(best.Key.Lang), dash(best.Key.Layout), dash(best.Key.Level),
+					best.WPM, best.Accuracy, best.Time.Local().Format("2006-01-02"))
+			}
+			if shown == 0 {
+				fmt.Println("No personal bests yet. Start typing: typ0 race")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&mode, "mode", "", "Only show one mode (words, quote, drill, learn or lesson)")
+	return cmd
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
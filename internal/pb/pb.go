@@ -0,0 +1,138 @@
+package pb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+)
+
+const bestsFile = "bests.json"
+
+// Key is what makes races comparable: the mode, how long the text was, the
+// word list, the layout emulated and the difficulty level texts were
+// picked for.
+type Key struct {
+	Mode      string `json:"mode"`
+	WordCount int    `json:"word_count,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+	Layout    string `json:"layout,omitempty"`
+	Level     string `json:"level,omitempty"`
+}
+
+func KeyFor(meta history.Meta) Key {
+	return Key{Mode: meta.Mode, WordCount: meta.WordCount, Lang: meta.Lang, Layout: meta.Layout, Level: meta.Level}
+}
+
+func recordKey(record history.Record) Key {
+	return Key{Mode: record.Mode, WordCount: record.WordCount, Lang: record.Lang, Layout: record.Layout, Level: record.Level}
+}
+
+func (k Key) id() string {
+	return fmt.Sprintf("%s/%d/%s/%s/%s", k.Mode, k.WordCount, k.Lang, k.Layout, k.Level)
+}
+
+func (k Key) String() string {
+	parts := []string{k.Mode}
+	if k.WordCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d words", k.WordCount))
+	}
+	if k.Lang != "" {
+		parts = append(parts, k.Lang)
+	}
+	if k.Layout != "" {
+		parts = append(parts, k.Layout)
+	}
+	if k.Level != "" {
+		parts = append(parts, k.Level+" texts")
+	}
+	return strings.Join(parts, ", ")
+}
+
+type Best struct {
+	Key      Key       `json:"key"`
+	WPM      float64   `json:"wpm"`
+	Accuracy float64   `json:"accuracy"`
+	Time     time.Time `json:"time"`
+}
+
+type Bests struct {
+	Bests map[string]*Best `json:"bests"`
+}
+
+// Load reads the personal bests, working them out from the history the
+// first time.
+func Load() (*Bests, error) {
+	var bests Bests
+	if err := storage.Load(bestsFile, &bests); err != nil {
+		return nil, err
+	}
+	if bests.Bests != nil {
+		return &bests, nil
+	}
+
+	bests.Bests = make(map[string]*Best)
+	records, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		bests.Update(recordKey(record), record.Stats, record.Time)
+	}
+	return &bests, nil
+}
+
+func (b *Bests) Save() error {
+	return storage.Save(bestsFile, b)
+}
+
+func (b *Bests) Get(key Key) *Best {
+	return b.Bests[key.id()]
+}
+
+// Update counts a race towards the personal best for key. It returns the
+// best the race was compared against, nil if there was none, and whether
+// the race beat it. Races ended early and follow-up retries do not count.
+func (b *Bests) Update(key Key, stats race.Stats, at time.Time) (*Best, bool) {
+	previous := b.Get(key)
+	if stats.Retry || !stats.Complete() {
+		return previous, false
+	}
+	if previous != nil && stats.WPM <= previous.WPM {
+		return previous, false
+	}
+	b.Bests[key.id()] = &Best{Key: key, WPM: stats.WPM, Accuracy: stats.Accuracy, Time: at.UTC()}
+	return previous, true
+}
+
+// All returns every personal best, grouped by mode and fastest first.
+func (b *Bests) All() []Best {
+	all := make([]Best, 0, len(b.Bests))
+	for _, best := range b.Bests {
+		all = append(all, *best)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Key.Mode != all[j].Key.Mode {
+			return all[i].Key.Mode < all[j].Key.Mode
+		}
+		return all[i].WPM > all[j].WPM
+	})
+	return all
+}
+
+// Imported counts races added to the history from elsewhere towards the
+// personal bests. It is a history import hook.
+func Imported(records []history.Record) error {
+	bests, err := Load()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		bests.Update(recordKey(record), record.Stats, record.Time)
+	}
+	return bests.Save()
+}
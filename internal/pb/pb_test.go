@@ -0,0 +1,70 @@
+package pb
+
+import (
+	"testing"
+	"time"
+
+	"go-typ0/internal/difficulty"
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+)
+
+func complete(wpm float64) race.Stats {
+	return race.Stats{Text: "abc", Input: "abc", WPM: wpm, Accuracy: 100, Finished: true}
+}
+
+func TestUpdate(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := Key{Mode: "words", WordCount: 25, Lang: "en"}
+	retry := complete(200)
+	retry.Retry = true
+
+	tests := []struct {
+		name     string
+		stats    race.Stats
+		improved bool
+		best     float64
+	}{
+		{"first", complete(50), true, 50},
+		{"slower", complete(40), false, 50},
+		{"equal", complete(50), false, 50},
+		{"faster", complete(60), true, 60},
+		{"ended early", race.Stats{Text: "abc", Input: "a", WPM: 200, Finished: true}, false, 60},
+		{"retry", retry, false, 60},
+	}
+	b := &Bests{Bests: make(map[string]*Best)}
+	for _, tt := range tests {
+		if _, improved := b.Update(key, tt.stats, at); improved != tt.improved {
+			t.Errorf("%s: improved = %v, want %v", tt.name, improved, tt.improved)
+		}
+		if got := b.Get(key); got == nil || got.WPM != tt.best {
+			t.Errorf("%s: best = %+v, want %v WPM", tt.name, got, tt.best)
+		}
+	}
+}
+
+func TestKeysKeepLevelsApart(t *testing.T) {
+	opts := race.Options{WordCount: 25}
+	normal := KeyFor(history.NewMeta("words", opts))
+	opts.Difficulty = difficulty.Easy
+	easy := KeyFor(history.NewMeta("words", opts))
+
+	if normal.id() == easy.id() {
+		t.Fatalf("races on easy texts share the key %q with normal ones", easy.id())
+	}
+
+	b := &Bests{Bests: make(map[string]*Best)}
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.Update(normal, complete(50), at)
+	if _, improved := b.Update(easy, complete(45), at); !improved {
+		t.Error("the first easy race is not a personal best of its own")
+	}
+	if b.Get(normal).WPM != 50 {
+		t.Errorf("normal best = %v, want 50", b.Get(normal).WPM)
+	}
+
+	record := history.NewRecord(history.NewMeta("words", opts), complete(45), at)
+	if recordKey(record) != easy {
+		t.Errorf("key of a saved easy race = %+v, want %+v", recordKey(record), easy)
+	}
+}
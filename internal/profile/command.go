@@ -0,0 +1,127 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-typ0/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles for people sharing a machine",
+		Long: `Each profile keeps its own history, personal bests, goals and learning
+progress. Pick one for a single run with --profile, or for every run with
+"typ0 profile switch".`,
+	}
+
+	cmd.AddCommand(newCreateCommand(), newListCommand(), newSwitchCommand(), newDeleteCommand())
+	return cmd
+}
+
+func newCreateCommand() *cobra.Command {
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := storage.CreateProfile(name); err != nil {
+				return err
+			}
+			fmt.Printf("Created profile %s\n", name)
+			if use {
+				return switchTo(name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&use, "switch", false, "Switch to the new profile")
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles, marking the active one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := storage.Profiles()
+			if err != nil {
+				return err
+			}
+			active, err := storage.ActiveProfile()
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+func newSwitchCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "switch <name>",
+		Short: "Use a profile from now on",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return switchTo(args[0])
+		},
+	}
+}
+
+func switchTo(name string) error {
+	if err := storage.SwitchProfile(name); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to profile %s\n", name)
+	return nil
+}
+
+func newDeleteCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a profile and all of its data",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if !yes && !confirm(fmt.Sprintf("Delete profile %s and all of its races and progress? [y/N] ", name)) {
+				fmt.Println("Nothing deleted")
+				return nil
+			}
+			if err := storage.DeleteProfile(name); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted profile %s\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+	return cmd
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,219 @@
+package race
+
+// Op is how one step of an alignment relates the text to the input.
+type Op int
+
+const (
+	OpMatch Op = iota
+	// OpSubstitution is a wrong character typed in place of the expected one.
+	OpSubstitution
+	// OpOmission is an expected character that was skipped.
+	OpOmission
+	// OpInsertion is an extra character that matches nothing in the text.
+	OpInsertion
+	// OpTransposition is two neighbouring characters typed the wrong way
+	// round. It covers two positions of both text and input.
+	OpTransposition
+)
+
+// Edit is one step of an alignment. Text and Input are the positions it
+// covers, -1 for an insertion's Text and an omission's Input.
+type Edit struct {
+	Op    Op
+	Text  int
+	Input int
+}
+
+// Alignment matches typed input against the start of the text. End is the
+// number of text characters the input covers, i.e. where typing continues.
+type Alignment struct {
+	Edits []Edit
+	End   int
+}
+
+// band is how far the input may run ahead of or fall behind the text.
+// Alignments further off the diagonal cost more than band mistakes and are
+// not considered, which keeps each keystroke's work independent of the
+// text's length.
+const band = 32
+
+// unreachable marks cells outside the band.
+const unreachable = int32(1 << 30)
+
+// Align finds the cheapest way to explain input as the beginning of text,
+// counting each substitution, omission, insertion and transposition as one
+// mistake. Among equally cheap explanations it keeps input and text
+// positions closest together, so in doubt a wrong key is a substitution.
+func Align(text, input []rune) Alignment {
+	a := newAligner(text)
+	for _, r := range input {
+		a.push(r)
+	}
+	return a.alignment()
+}
+
+// aligner aligns input with text as it is typed. It keeps one row of the
+// edit distance table per input character, restricted to the band around
+// the diagonal, so typing a character adds a row and deleting one drops
+// it.
+type aligner struct {
+	text  []rune
+	input []rune
+	// rows[i] holds the costs of aligning input[:i] with text[:j] for j
+	// from lo(i) to hi(i).
+	rows [][]int32
+}
+
+func newAligner(text []rune) *aligner {
+	a := &aligner{text: text}
+	row := make([]int32, a.hi(0)+1)
+	for j := range row {
+		row[j] = int32(j)
+	}
+	a.rows = append(a.rows, row)
+	return a
+}
+
+// lo and hi bound the columns of row i. Once the input outruns the text the
+// band stays at its end, so runaway input still lines up with it.
+func (a *aligner) lo(i int) int {
+	return max(0, min(i, len(a.text))-band)
+}
+
+func (a *aligner) hi(i int) int {
+	return min(len(a.text), i+band)
+}
+
+func (a *aligner) at(i, j int) int32 {
+	if j < a.lo(i) || j > a.hi(i) {
+		return unreachable
+	}
+	return a.rows[i][j-a.lo(i)]
+}
+
+func (a *aligner) push(r rune) {
+	a.input = append(a.input, r)
+	i, lo, hi := len(a.input), a.lo(len(a.input)), a.hi(len(a.input))
+
+	// Reuse the row dropped by the last pop, if any.
+	var row []int32
+	if len(a.rows) < cap(a.rows) {
+		row = a.rows[:len(a.rows)+1][len(a.rows)][:0]
+	}
+	for j := lo; j <= hi; j++ {
+		if j == 0 {
+			row = append(row, int32(i))
+			continue
+		}
+		cost := a.at(i-1, j-1)
+		if r != a.text[j-1] {
+			cost++
+		}
+		if c := a.at(i-1, j) + 1; c < cost {
+			cost = c
+		}
+		if j > lo {
+			if c := row[j-1-lo] + 1; c < cost {
+				cost = c
+			}
+		}
+		if transposed(a.text, a.input, i, j) {
+			if c := a.at(i-2, j-2) + 1; c < cost {
+				cost = c
+			}
+		}
+		if cost > unreachable {
+			cost = unreachable
+		}
+		row = append(row, cost)
+	}
+	a.rows = append(a.rows, row)
+}
+
+func (a *aligner) pop() {
+	if len(a.input) == 0 {
+		return
+	}
+	a.input = a.input[:len(a.input)-1]
+	a.rows = a.rows[:len(a.rows)-1]
+}
+
+func (a *aligner) alignment() Alignment {
+	text, input := a.text, a.input
+	n := len(input)
+
+	end := -1
+	for j := a.lo(n); j <= a.hi(n); j++ {
+		if end < 0 || a.at(n, j) < a.at(n, end) || (a.at(n, j) == a.at(n, end) && distance(j, n) < distance(end, n)) {
+			end = j
+		}
+	}
+
+	var edits []Edit
+	for i, j := n, end; i > 0 || j > 0; {
+		cost := a.at(i, j)
+		switch {
+		case i > 0 && j > 0 && input[i-1] == text[j-1] && cost == a.at(i-1, j-1):
+			edits = append(edits, Edit{OpMatch, j - 1, i - 1})
+			i, j = i-1, j-1
+		case transposed(text, input, i, j) && cost == a.at(i-2, j-2)+1:
+			edits = append(edits, Edit{OpTransposition, j - 2, i - 2})
+			i, j = i-2, j-2
+		case i > 0 && j > 0 && cost == a.at(i-1, j-1)+1:
+			edits = append(edits, Edit{OpSubstitution, j - 1, i - 1})
+			i, j = i-1, j-1
+		case i > 0 && cost == a.at(i-1, j)+1:
+			edits = append(edits, Edit{OpInsertion, -1, i - 1})
+			i--
+		default:
+			edits = append(edits, Edit{OpOmission, j - 1, -1})
+			j--
+		}
+	}
+	for l, r := 0, len(edits)-1; l < r; l, r = l+1, r-1 {
+		edits[l], edits[r] = edits[r], edits[l]
+	}
+
+	return Alignment{Edits: edits, End: end}
+}
+
+func transposed(text, input []rune, i, j int) bool {
+	return i > 1 && j > 1 &&
+		input[i-1] == text[j-2] && input[i-2] == text[j-1] && input[i-1] != input[i-2]
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// ErrorCounts classifies the mistakes left in the final input.
+type ErrorCounts struct {
+	Substitutions  int `json:"substitutions"`
+	Omissions      int `json:"omissions"`
+	Insertions     int `json:"insertions"`
+	Transpositions int `json:"transpositions"`
+}
+
+func (a Alignment) Errors() ErrorCounts {
+	var counts ErrorCounts
+	for _, edit := range a.Edits {
+		switch edit.Op {
+		case OpSubstitution:
+			counts.Substitutions++
+		case OpOmission:
+			counts.Omissions++
+		case OpInsertion:
+			counts.Insertions++
+		case OpTransposition:
+			counts.Transpositions++
+		}
+	}
+	return counts
+}
+
+func (e ErrorCounts) Total() int {
+	return e.Substitutions + e.Omissions + e.Insertions + e.Transpositions
+}
@@ -0,0 +1,186 @@
+package race
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		input  string
+		end    int
+		errors ErrorCounts
+		edits  []Edit
+	}{
+		{
+			name:  "empty input",
+			text:  "abc",
+			input: "",
+			end:   0,
+		},
+		{
+			name:  "exact prefix",
+			text:  "hello world",
+			input: "hello",
+			end:   5,
+			edits: []Edit{{OpMatch, 0, 0}, {OpMatch, 1, 1}, {OpMatch, 2, 2}, {OpMatch, 3, 3}, {OpMatch, 4, 4}},
+		},
+		{
+			name:   "substitution",
+			text:   "cat",
+			input:  "cot",
+			end:    3,
+			errors: ErrorCounts{Substitutions: 1},
+			edits:  []Edit{{OpMatch, 0, 0}, {OpSubstitution, 1, 1}, {OpMatch, 2, 2}},
+		},
+		{
+			name:   "transposition",
+			text:   "the cat",
+			input:  "hte cat",
+			end:    7,
+			errors: ErrorCounts{Transpositions: 1},
+		},
+		{
+			name:   "omission",
+			text:   "typing",
+			input:  "tping",
+			end:    6,
+			errors: ErrorCounts{Omissions: 1},
+			edits:  []Edit{{OpMatch, 0, 0}, {OpOmission, 1, -1}, {OpMatch, 2, 1}, {OpMatch, 3, 2}, {OpMatch, 4, 3}, {OpMatch, 5, 4}},
+		},
+		{
+			name:   "insertion",
+			text:   "typing",
+			input:  "tyyping",
+			end:    6,
+			errors: ErrorCounts{Insertions: 1},
+		},
+		{
+			name:   "skipped word",
+			text:   "one two three four",
+			input:  "one three four",
+			end:    18,
+			errors: ErrorCounts{Omissions: 4},
+		},
+		{
+			name:   "in doubt a substitution",
+			text:   "abcd",
+			input:  "ax",
+			end:    2,
+			errors: ErrorCounts{Substitutions: 1},
+		},
+		{
+			name:   "runaway input",
+			text:   "abc",
+			input:  "abcxyzxyz",
+			end:    3,
+			errors: ErrorCounts{Insertions: 6},
+		},
+		{
+			name:   "input unrelated to the text",
+			text:   "abc",
+			input:  "xyzxyz",
+			end:    3,
+			errors: ErrorCounts{Substitutions: 3, Insertions: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Align([]rune(tt.text), []rune(tt.input))
+			if got.End != tt.end {
+				t.Errorf("End = %d, want %d", got.End, tt.end)
+			}
+			if errors := got.Errors(); errors != tt.errors {
+				t.Errorf("Errors = %+v, want %+v", errors, tt.errors)
+			}
+			if tt.edits != nil && !reflect.DeepEqual(got.Edits, tt.edits) {
+				t.Errorf("Edits = %v, want %v", got.Edits, tt.edits)
+			}
+		})
+	}
+}
+
+func TestAlignRunawayLongText(t *testing.T) {
+	text := []rune(strings.Repeat("lorem ipsum ", 20))
+	input := append(append([]rune(nil), text...), []rune(strings.Repeat("q", len(text)))...)
+	got := Align(text, input)
+	if got.End != len(text) {
+		t.Errorf("End = %d, want %d", got.End, len(text))
+	}
+	if errors := got.Errors(); errors != (ErrorCounts{Insertions: len(text)}) {
+		t.Errorf("Errors = %+v, want %d insertions", errors, len(text))
+	}
+}
+
+// TestAlignerIncremental types and deletes characters one at a time and
+// checks the result against aligning the whole input at once and against
+// the full edit distance table.
+func TestAlignerIncremental(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	text := []rune(strings.Repeat("the quick brown fox jumps over the lazy dog ", 3))
+	a := newAligner(text)
+	var input []rune
+	for step := 0; step < 2000; step++ {
+		if len(input) > 0 && rng.Intn(5) == 0 {
+			input = input[:len(input)-1]
+			a.pop()
+		} else {
+			r := 'x'
+			if next := len(input); next < len(text) && rng.Intn(10) > 0 {
+				r = text[next]
+			}
+			input = append(input, r)
+			a.push(r)
+		}
+		if len(input) >= len(text) {
+			continue
+		}
+
+		got := a.alignment()
+		if want := Align(text, input); !reflect.DeepEqual(got, want) {
+			t.Fatalf("step %d: incremental %+v, from scratch %+v", step, got, want)
+		}
+		if end, cost := fullAlign(text, input); got.End != end || got.Errors().Total() != cost {
+			t.Fatalf("step %d: banded End %d with %d errors, full table End %d with %d errors",
+				step, got.End, got.Errors().Total(), end, cost)
+		}
+	}
+}
+
+// fullAlign fills the whole edit distance table, without the band, and
+// returns where the input ends in the text and the number of mistakes.
+func fullAlign(text, input []rune) (end, cost int) {
+	n, m := len(input), len(text)
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := d[i-1][j-1]
+			if input[i-1] != text[j-1] {
+				cost++
+			}
+			cost = min(cost, min(d[i-1][j], d[i][j-1])+1)
+			if transposed(text, input, i, j) {
+				cost = min(cost, d[i-2][j-2]+1)
+			}
+			d[i][j] = cost
+		}
+	}
+
+	for j := 1; j <= m; j++ {
+		if d[n][j] < d[n][end] || (d[n][j] == d[n][end] && distance(j, n) < distance(end, n)) {
+			end = j
+		}
+	}
+	return end, d[n][end]
+}
@@ -4,12 +4,52 @@ import (
 	"fmt"
 	"os"
 
+	"go-typ0/internal/difficulty"
+	"go-typ0/internal/words"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
+// RecorderFunc hooks persistence into a view model. The returned function
+// reports problems saving races once the program has exited.
+type RecorderFunc func(vm *ViewModel, mode string, opts Options) func() error
+
+// recorders are installed by main, which keeps this package free of
+// storage.
+var recorders []RecorderFunc
+
+func AddRecorder(fn RecorderFunc) {
+	recorders = append(recorders, fn)
+}
+
+// Record attaches the installed recorders, in the order they were added, to
+// vm. The returned function reports the first problem any of them had.
+func Record(vm *ViewModel, mode string, opts Options) func() error {
+	saveErrs := make([]func() error, len(recorders))
+	for i, recorder := range recorders {
+		saveErrs[i] = recorder(vm, mode, opts)
+	}
+	return func() error {
+		for _, saveErr := range saveErrs {
+			if err := saveErr(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func NewCommand() *cobra.Command {
-	var wordCount int
+	var (
+		wordCount int
+		lang      string
+		langDir   string
+		quote     bool
+		rounds    int
+		level     string
+		flags     Flags
+	)
 
 	cmd := &cobra.Command{
 		Use:     "race",
@@ -17,18 +57,57 @@ func NewCommand() *cobra.Command {
 		Short:   "Start a typing race",
 		Long:    `Start a typing race with random sentences. Race against time to improve your typing speed!`,
 		Run: func(cmd *cobra.Command, args []string) {
-			model := NewModel(wordCount)
+			pack, err := words.Resolve(lang, langDir)
+			if err != nil {
+				fmt.Println("Error loading language pack: ", err)
+				os.Exit(1)
+			}
+
+			opts := Options{
+				WordCount: wordCount,
+				Pack:      pack,
+				Quote:     quote,
+			}
+			err = flags.Apply(&opts)
+			if err == nil && level != "" {
+				opts.Difficulty, err = difficulty.ParseLevel(level)
+			}
+			if err != nil {
+				fmt.Println("Error: ", err)
+				os.Exit(1)
+			}
+
+			model := NewModel(opts)
 			viewModel := NewViewModel(model)
 
-			p := tea.NewProgram(viewModel)
+			mode := "words"
+			if quote {
+				mode = "quote"
+			}
+			saveErr := Record(viewModel, mode, opts)
+
+			var program tea.Model = viewModel
+			if rounds > 1 {
+				program = NewSession(viewModel, rounds)
+			}
+			p := tea.NewProgram(program)
 			if _, err := p.Run(); err != nil {
 				fmt.Println("Error running program: ", err)
 				os.Exit(1)
 			}
+			if err := saveErr(); err != nil {
+				fmt.Println("Error saving history: ", err)
+			}
 		},
 	}
 
 	cmd.Flags().IntVarP(&wordCount, "words", "w", 20, "Number of words in the sentence")
+	cmd.Flags().StringVarP(&lang, "lang", "l", "", "Language pack to practise (defaults to $LANG)")
+	cmd.Flags().StringVar(&langDir, "lang-dir", "", "Extra directory to search for language packs")
+	cmd.Flags().BoolVarP(&quote, "quote", "q", false, "Type a quote from the language pack instead of random words")
+	cmd.Flags().StringVar(&level, "difficulty", "", "Pick texts of this difficulty: easy, medium or hard")
+	cmd.Flags().IntVar(&rounds, "rounds", 1, "Race this many times back to back, then sum up the session")
+	flags.Register(cmd)
 
 	return cmd
-} 
\ No newline at end of file
+}
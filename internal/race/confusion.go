@@ -0,0 +1,101 @@
+package race
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// shownConfusions is how many confusions the results screen lists.
+const shownConfusions = 5
+
+// Confusion counts how often Typed was pressed where Expected was due.
+type Confusion struct {
+	Expected rune
+	Typed    rune
+	Count    int
+}
+
+type confusionKey struct {
+	expected, typed rune
+}
+
+func (c Confusion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Expected string `json:"expected"`
+		Typed    string `json:"typed"`
+		Count    int    `json:"count"`
+	}{runeString(c.Expected), runeString(c.Typed), c.Count})
+}
+
+func (c *Confusion) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Expected string `json:"expected"`
+		Typed    string `json:"typed"`
+		Count    int    `json:"count"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Confusion{Expected: firstRune(raw.Expected), Typed: firstRune(raw.Typed), Count: raw.Count}
+	return nil
+}
+
+func sortConfusions(counts map[confusionKey]int) []Confusion {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	confusions := make([]Confusion, 0, len(counts))
+	for key, count := range counts {
+		confusions = append(confusions, Confusion{Expected: key.expected, Typed: key.typed, Count: count})
+	}
+	sort.Slice(confusions, func(i, j int) bool {
+		a, b := confusions[i], confusions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Expected != b.Expected {
+			return a.Expected < b.Expected
+		}
+		return a.Typed < b.Typed
+	})
+	return confusions
+}
+
+// MergeConfusions adds up the confusions of several races, most common
+// first.
+func MergeConfusions(races []Stats) []Confusion {
+	counts := make(map[confusionKey]int)
+	for _, stats := range races {
+		for _, c := range stats.Confusions {
+			counts[confusionKey{c.Expected, c.Typed}] += c.Count
+		}
+	}
+	return sortConfusions(counts)
+}
+
+// renderConfusions lists the most common confusions of this race or, with
+// the history shown, of every saved race.
+func (vm *ViewModel) renderConfusions(stats Stats) string {
+	confusions := stats.Confusions
+	if vm.showHistory && vm.history != nil {
+		confusions = MergeConfusions(vm.history())
+	}
+	if len(confusions) == 0 {
+		return ""
+	}
+
+	lines := []string{vm.styles.LabelStyle.Render("Typed instead: ")}
+	for i, c := range confusions {
+		if i >= shownConfusions {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("- %s for %s %s",
+			vm.styles.MistypedKeyStyle.Render(fmt.Sprintf("%q", c.Typed)),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("%q", c.Expected)),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("%d", c.Count))))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package race
+
+import (
+	"fmt"
+	"sort"
+
+	"go-typ0/internal/difficulty"
+)
+
+const (
+	// difficultyTries is how many texts are made up looking for one in the
+	// chosen band before settling for the closest.
+	difficultyTries = 50
+	// minPool is the fewest words random texts are drawn from.
+	minPool = 30
+)
+
+// pickText makes up a text, trying for one in level's difficulty band.
+func (m *Model) pickText(level difficulty.Level) string {
+	if level == difficulty.Any {
+		return m.generateText()
+	}
+	if m.source == nil && !m.quote && m.pool == nil {
+		m.pool = m.wordPool(level)
+	}
+
+	best, bestDistance := "", 0.0
+	for i := 0; i < difficultyTries; i++ {
+		text := m.generateText()
+		distance := level.Distance(m.scorer.Score(text).Total)
+		if i == 0 || distance < bestDistance {
+			best, bestDistance = text, distance
+		}
+		if distance == 0 {
+			break
+		}
+	}
+	return best
+}
+
+// wordPool picks the pack's words in level's band, or the closest ones
+// when too few are in it, so random texts mostly land in the band.
+func (m *Model) wordPool(level difficulty.Level) []string {
+	type scored struct {
+		word     string
+		distance float64
+	}
+	words := make([]scored, len(m.pack.Words))
+	inBand := 0
+	for i, word := range m.pack.Words {
+		words[i] = scored{word, level.Distance(m.scorer.Score(word).Total)}
+		if words[i].distance == 0 {
+			inBand++
+		}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return words[i].distance < words[j].distance })
+
+	n := min(len(words), max(inBand, minPool))
+	pool := make([]string, n)
+	for i := range pool {
+		pool[i] = words[i].word
+	}
+	return pool
+}
+
+func (vm *ViewModel) renderDifficulty() string {
+	total := vm.model.score.Total
+	return fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Difficulty:"),
+		vm.styles.ValueStyle.Render(fmt.Sprintf("%.1f (%s)", total, difficulty.LevelOf(total))))
+}
@@ -0,0 +1,205 @@
+package race
+
+import (
+	"fmt"
+	"strings"
+
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/layout"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minFingerPresses keeps a finger with a handful of presses from being
+// called the weakest.
+const minFingerPresses = 5
+
+// FingerReport breaks a race down by the finger expected to press each key.
+// SameFinger and OtherFinger time consecutive correct presses of two
+// different keys with the same finger and with different fingers, so their
+// averages show how much same-finger bigrams slow you down. Transitions
+// counts pairs of consecutive letters and Alternations those typed with
+// different hands.
+type FingerReport struct {
+	Fingers      map[keyboard.Finger]KeyStat `json:"fingers"`
+	SameFinger   KeyStat                     `json:"same_finger"`
+	OtherFinger  KeyStat                     `json:"other_finger"`
+	Transitions  int                         `json:"transitions"`
+	Alternations int                         `json:"alternations"`
+}
+
+// AnalyzeFingers maps every keystroke to its finger on layout l. Characters
+// not on the layout are left out.
+func AnalyzeFingers(keystrokes []Keystroke, l *layout.Layout, fm *keyboard.FingerMap) *FingerReport {
+	report := &FingerReport{Fingers: make(map[keyboard.Finger]KeyStat)}
+	presses := make(map[rune]keyboard.Press)
+	locate := func(r rune) (keyboard.Press, bool) {
+		if press, ok := presses[r]; ok {
+			return press, true
+		}
+		press, ok := keyboard.Locate(r, l, fm)
+		if ok {
+			presses[r] = press
+		}
+		return press, ok
+	}
+
+	stats := Stats{Keystrokes: keystrokes}
+	for r, stat := range stats.KeyStats() {
+		press, ok := locate(r)
+		if !ok {
+			continue
+		}
+		total := report.Fingers[press.Finger]
+		total.Add(stat)
+		report.Fingers[press.Finger] = total
+	}
+
+	var prev *Keystroke
+	for i := range keystrokes {
+		key := &keystrokes[i]
+		if key.Backspace {
+			prev = nil
+			continue
+		}
+		if prev != nil && prev.Index+1 == key.Index && prev.Correct() && key.Correct() {
+			report.addBigram(*prev, *key, locate)
+		}
+		prev = key
+	}
+	return report
+}
+
+func (r *FingerReport) addBigram(prev, key Keystroke, locate func(rune) (keyboard.Press, bool)) {
+	if prev.Expected == ' ' || key.Expected == ' ' || prev.Expected == '\n' || key.Expected == '\n' {
+		return
+	}
+	from, ok := locate(prev.Expected)
+	if !ok {
+		return
+	}
+	to, ok := locate(key.Expected)
+	if !ok {
+		return
+	}
+
+	r.Transitions++
+	if from.Finger.Hand() != to.Finger.Hand() {
+		r.Alternations++
+	}
+
+	if from.Key == to.Key {
+		return
+	}
+	stat := &r.OtherFinger
+	if from.Finger == to.Finger {
+		stat = &r.SameFinger
+	}
+	stat.Presses++
+	if latency := key.At - prev.At; latency <= maxLatency {
+		stat.Latency += latency
+		stat.Timed++
+	}
+}
+
+func (r *FingerReport) Add(other *FingerReport) {
+	if other == nil {
+		return
+	}
+	if r.Fingers == nil {
+		r.Fingers = make(map[keyboard.Finger]KeyStat)
+	}
+	for finger, stat := range other.Fingers {
+		total := r.Fingers[finger]
+		total.Add(stat)
+		r.Fingers[finger] = total
+	}
+	r.SameFinger.Add(other.SameFinger)
+	r.OtherFinger.Add(other.OtherFinger)
+	r.Transitions += other.Transitions
+	r.Alternations += other.Alternations
+}
+
+// AlternationRate is the share of letter pairs typed with different hands.
+func (r *FingerReport) AlternationRate() float64 {
+	if r.Transitions == 0 {
+		return 0
+	}
+	return float64(r.Alternations) / float64(r.Transitions)
+}
+
+// SameFingerSlowdown is how many times longer a same-finger bigram takes
+// than a bigram typed with two fingers, or 0 without enough data.
+func (r *FingerReport) SameFingerSlowdown() float64 {
+	same, other := r.SameFinger.AverageLatency(), r.OtherFinger.AverageLatency()
+	if same == 0 || other == 0 {
+		return 0
+	}
+	return float64(same) / float64(other)
+}
+
+// Weakest picks the finger with the highest error rate, the slower one on a
+// tie, among fingers pressed often enough to judge.
+func (r *FingerReport) Weakest() (keyboard.Finger, KeyStat, bool) {
+	var (
+		weakest keyboard.Finger
+		worst   KeyStat
+		found   bool
+	)
+	for _, finger := range keyboard.Fingers() {
+		stat, ok := r.Fingers[finger]
+		if !ok || stat.Presses < minFingerPresses {
+			continue
+		}
+		if !found || stat.ErrorRate() > worst.ErrorRate() ||
+			(stat.ErrorRate() == worst.ErrorRate() && stat.AverageLatency() > worst.AverageLatency()) {
+			weakest, worst, found = finger, stat, true
+		}
+	}
+	return weakest, worst, found
+}
+
+// MergeFingerReports adds up the finger reports of several races.
+func MergeFingerReports(races []Stats) *FingerReport {
+	total := &FingerReport{Fingers: make(map[keyboard.Finger]KeyStat)}
+	for _, stats := range races {
+		total.Add(stats.Fingers)
+	}
+	return total
+}
+
+// renderFingers sums up the finger analysis for this race or, with the
+// history shown, for every saved race.
+func (vm *ViewModel) renderFingers(stats Stats) string {
+	report := stats.Fingers
+	if vm.showHistory && vm.history != nil {
+		report = MergeFingerReports(vm.history())
+	}
+	if report == nil {
+		return ""
+	}
+
+	var lines []string
+	if finger, stat, ok := report.Weakest(); ok {
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			vm.styles.LabelStyle.Render("Weakest finger:"),
+			lipgloss.NewStyle().Foreground(finger.Color()).Render(finger.String()),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("(%.0f%% errors, %dms)", 100*stat.ErrorRate(), stat.AverageLatency().Milliseconds()))))
+	}
+
+	var details []string
+	if slowdown := report.SameFingerSlowdown(); slowdown > 0 {
+		details = append(details, fmt.Sprintf("%s %s",
+			vm.styles.LabelStyle.Render("Same-finger bigrams:"),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("%.1fx slower", slowdown))))
+	}
+	if report.Transitions > 0 {
+		details = append(details, fmt.Sprintf("%s %s",
+			vm.styles.LabelStyle.Render("Hand alternation:"),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("%.0f%%", 100*report.AlternationRate()))))
+	}
+	if len(details) > 0 {
+		lines = append(lines, strings.Join(details, "  "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+package race
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/layout"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags are the options shared by every command that runs races.
+type Flags struct {
+	Layout   string
+	Keyboard string
+	Fingers  string
+	Guide    bool
+	Tags     []string
+}
+
+func (f *Flags) Register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Layout, "layout", "", "Emulate a keyboard layout on a QWERTY keyboard (colemak, dvorak, workman or a layout file)")
+	cmd.Flags().StringVar(&f.Keyboard, "keyboard", "ansi", "Physical keyboard to draw (ansi, iso or ortho)")
+	cmd.Flags().StringVar(&f.Fingers, "fingers", "", "Finger map file assigning fingers to keys (defaults to standard touch typing)")
+	cmd.Flags().BoolVar(&f.Guide, "guide", false, "Show a keyboard with the next key and finger while typing")
+	cmd.Flags().StringArrayVar(&f.Tags, "tag", nil, "Tag the saved races, e.g. with the keyboard used (repeatable)")
+}
+
+// Apply fills in the parts of opts the flags control.
+func (f *Flags) Apply(opts *Options) error {
+	if f.Layout != "" {
+		lay, err := layout.Load(f.Layout)
+		if err != nil {
+			return err
+		}
+		opts.Layout = lay
+	}
+
+	geometry, err := keyboard.Lookup(f.Keyboard)
+	if err != nil {
+		return err
+	}
+	opts.Keyboard = geometry
+
+	opts.Fingers = keyboard.StandardFingers()
+	if f.Fingers != "" {
+		if opts.Fingers, err = keyboard.LoadFingers(f.Fingers); err != nil {
+			return err
+		}
+	}
+	opts.Guide = f.Guide
+
+	opts.Tags, err = ParseTags(f.Tags)
+	return err
+}
+
+// ParseTags cleans up tags given on the command line: they are trimmed and
+// lower-cased, and repeats are dropped.
+func ParseTags(tags []string) ([]string, error) {
+	var parsed []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.ContainsAny(tag, ",\x00") {
+			return nil, fmt.Errorf("invalid tag %q: tags must not be empty or contain commas", tag)
+		}
+		if !slices.Contains(parsed, tag) {
+			parsed = append(parsed, tag)
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,133 @@
+package race
+
+import (
+	"fmt"
+
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/layout"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m *Model) keyboardLayout() *layout.Layout {
+	if m.layout != nil {
+		return m.layout
+	}
+	return layout.QWERTY()
+}
+
+func (m *Model) keyboardFingers() *keyboard.FingerMap {
+	if m.fingers != nil {
+		return m.fingers
+	}
+	return keyboard.StandardFingers()
+}
+
+func (vm *ViewModel) keyboardLayout() *layout.Layout {
+	return vm.model.keyboardLayout()
+}
+
+func (vm *ViewModel) keyboardFingers() *keyboard.FingerMap {
+	return vm.model.keyboardFingers()
+}
+
+func (vm *ViewModel) keyboardGeometry() *keyboard.Geometry {
+	if vm.model.keyboard != nil {
+		return vm.model.keyboard
+	}
+	geometry, _ := keyboard.Lookup("ansi")
+	return geometry
+}
+
+// renderGuide draws the keyboard with the next key to press highlighted and
+// names the finger, and Shift key, to press it with.
+func (vm *ViewModel) renderGuide() string {
+	end := vm.model.alignment.End
+	if end >= len(vm.model.sentence) {
+		return ""
+	}
+	next := vm.model.sentence[end]
+	if next == '\n' {
+		next = ' '
+	}
+
+	lay := vm.keyboardLayout()
+	fingers := vm.keyboardFingers()
+	press, ok := keyboard.Locate(next, lay, fingers)
+
+	caption := fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Next:"), vm.styles.ValueStyle.Render(fmt.Sprintf("%q", next)))
+	if ok {
+		caption += "  " + lipgloss.NewStyle().Foreground(press.Finger.Color()).Render(press.Finger.String())
+		if press.Shift != 0 {
+			caption += " + " + lipgloss.NewStyle().Foreground(press.ShiftFinger.Color()).Render(press.ShiftFinger.Hand().String()+" shift")
+		}
+	}
+
+	return caption + "\n" + vm.keyboardGeometry().Render(lay, keyboard.Guide(lay, fingers, press))
+}
+
+// renderHeatmaps draws the keyboard twice, coloured by error rate and by
+// average latency, for this race or for every race in the history.
+func (vm *ViewModel) renderHeatmaps(stats Stats) string {
+	keys := stats.KeyStats()
+	title := "This race"
+	if vm.showHistory && vm.history != nil {
+		races := vm.history()
+		if vm.keys != nil {
+			keys = vm.keys()
+		} else {
+			keys = MergeKeyStats(races)
+		}
+		title = fmt.Sprintf("All races (%d)", len(races))
+	}
+
+	lay := vm.keyboardLayout()
+	byKey := make(map[rune]KeyStat)
+	for r, stat := range keys {
+		base := r
+		if r != ' ' {
+			var ok bool
+			if base, ok = lay.Base(r); !ok {
+				continue
+			}
+		}
+		total := byKey[base]
+		total.Add(stat)
+		byKey[base] = total
+	}
+
+	errorRates := make(map[rune]float64)
+	latencies := make(map[rune]float64)
+	for r, stat := range byKey {
+		if stat.Presses > 0 {
+			errorRates[r] = stat.ErrorRate()
+		}
+		if stat.Timed > 0 {
+			latencies[r] = float64(stat.AverageLatency().Milliseconds())
+		}
+	}
+
+	geometry := vm.keyboardGeometry()
+	errorsMap := vm.styles.LabelStyle.Render("Errors") + "\n" + geometry.Render(lay, keyboard.Heat(errorRates))
+	latencyMap := vm.styles.LabelStyle.Render("Latency") + "\n" + geometry.Render(lay, keyboard.Heat(latencies))
+
+	// Side by side needs room for both keyboards plus the stats box border
+	// and padding; narrower terminals show one map at a time.
+	var maps string
+	if vm.wideHeatmaps() {
+		maps = lipgloss.JoinHorizontal(lipgloss.Top, errorsMap, "    ", latencyMap)
+	} else if vm.showLatency {
+		maps = latencyMap
+	} else {
+		maps = errorsMap
+	}
+
+	header := fmt.Sprintf("%s %s  %s", vm.styles.LabelStyle.Render("Keyboard:"), vm.styles.ValueStyle.Render(title), keyboard.Legend())
+	return header + "\n" + maps
+}
+
+func (vm *ViewModel) wideHeatmaps() bool {
+	plain := func(rune) lipgloss.Style { return lipgloss.NewStyle() }
+	width := lipgloss.Width(vm.keyboardGeometry().Render(vm.keyboardLayout(), plain))
+	return vm.model.width == 0 || vm.model.width >= 2*width+12
+}
@@ -5,38 +5,123 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"go-typ0/internal/difficulty"
+	"go-typ0/internal/keyboard"
+	"go-typ0/internal/layout"
 	"go-typ0/internal/words"
 )
 
+type Options struct {
+	WordCount int
+	Pack      *words.Pack
+	Quote     bool
+	// Layout, when set, is emulated on a physical QWERTY keyboard.
+	Layout *layout.Layout
+	// Keyboard is the physical keyboard drawn on screen.
+	Keyboard *keyboard.Geometry
+	// Fingers assigns keys to fingers; Guide shows them while typing.
+	Fingers *keyboard.FingerMap
+	Guide   bool
+	// Tags label the saved races, such as with the keyboard used.
+	Tags []string
+	// Difficulty, when set, picks texts whose difficulty score falls in
+	// the level's band where possible.
+	Difficulty difficulty.Level
+	// Source, when set, produces the text for each race instead of the
+	// random words or quotes taken from Pack.
+	Source func() string
+}
+
 type Model struct {
-	input     string
-	startTime time.Time
-	finished  bool
-	mistyped  map[rune]int
-	sentence  string
-	width     int
-	height    int
-	wordCount int
-	totalKeystrokes int
+	input             []rune
+	aligner           *aligner
+	alignment         Alignment
+	startTime         time.Time
+	endTime           time.Time
+	finished          bool
+	mistyped          map[rune]int
+	confusions        map[confusionKey]int
+	sentence          []rune
+	width             int
+	height            int
+	wordCount         int
+	pack              *words.Pack
+	quote             bool
+	source            func() string
+	retry             string
+	retrying          bool
+	layout            *layout.Layout
+	keyboard          *keyboard.Geometry
+	fingers           *keyboard.FingerMap
+	guide             bool
+	level             difficulty.Level
+	scorer            *difficulty.Scorer
+	score             difficulty.Score
+	pool              []string
+	totalKeystrokes   int
 	correctKeystrokes int
+	keystrokes        []Keystroke
+}
+
+// LayoutName returns the name of the emulated layout, or "" for none.
+func (o Options) LayoutName() string {
+	if o.Layout == nil {
+		return ""
+	}
+	return o.Layout.Name
 }
 
-func NewModel(wordCount int) *Model {
-	return &Model{
-		wordCount: wordCount,
-		mistyped:  make(map[rune]int),
+func NewModel(opts Options) *Model {
+	pack := opts.Pack
+	if pack == nil {
+		pack = &words.Pack{Code: words.DefaultLang, Name: "English", Words: words.Words}
 	}
+
+	m := &Model{
+		wordCount:  opts.WordCount,
+		pack:       pack,
+		quote:      opts.Quote,
+		source:     opts.Source,
+		layout:     opts.Layout,
+		keyboard:   opts.Keyboard,
+		fingers:    opts.Fingers,
+		guide:      opts.Guide,
+		level:      opts.Difficulty,
+		mistyped:   make(map[rune]int),
+		confusions: make(map[confusionKey]int),
+	}
+	m.scorer = difficulty.NewScorer(m.keyboardLayout(), m.keyboardFingers(), pack.Words)
+	return m
 }
 
 func (m *Model) Init() {
 	m.startTime = time.Now()
 	m.mistyped = make(map[rune]int)
-	m.sentence = m.generateRandomSentence()
+	m.confusions = make(map[confusionKey]int)
+	m.retrying = m.retry != ""
+	m.sentence = []rune(m.generateRandomSentence())
+	m.score = m.scorer.Score(string(m.sentence))
 	m.finished = false
-	m.input = ""
+	m.input = nil
+	m.aligner = newAligner(m.sentence)
+	m.alignment = Alignment{}
 	m.totalKeystrokes = 0
 	m.correctKeystrokes = 0
+	m.keystrokes = nil
+}
+
+func (m *Model) Finished() bool {
+	return m.finished
+}
+
+func (m *Model) Finish() {
+	if m.finished {
+		return
+	}
+	m.finished = true
+	m.endTime = time.Now()
 }
 
 func (m *Model) GetStats() Stats {
@@ -44,16 +129,25 @@ func (m *Model) GetStats() Stats {
 		return Stats{}
 	}
 
-	duration := time.Since(m.startTime)
+	duration := m.endTime.Sub(m.startTime)
 	accuracy := m.calculateAccuracy()
 	wpm := m.calculateWPM(duration)
 
 	return Stats{
-		Duration:  duration,
-		Accuracy:  accuracy,
-		WPM:       wpm,
-		Mistyped:  m.getTopMistyped(5),
-		Finished:  true,
+		Duration:   duration,
+		Accuracy:   accuracy,
+		WPM:        wpm,
+		Mistyped:   m.getTopMistyped(5),
+		Confusions: sortConfusions(m.confusions),
+		Text:       string(m.sentence),
+		Input:      string(m.input),
+		Keystrokes: m.keystrokes,
+		Errors:     m.alignment.Errors(),
+		Words:      wordStats(m.sentence, m.keystrokes),
+		Retry:      m.retrying,
+		Difficulty: m.score.Total,
+		Fingers:    AnalyzeFingers(m.keystrokes, m.keyboardLayout(), m.keyboardFingers()),
+		Finished:   true,
 	}
 }
 
@@ -62,24 +156,35 @@ func (m *Model) HandleInput(input string) {
 		return
 	}
 
-	if len(input) == 1 && len(m.input) < len(m.sentence) {
-		expected := string(m.sentence[len(m.input)])
-		
+	typed := []rune(input)
+	if len(typed) == 1 && m.alignment.End < len(m.sentence) {
+		expected := m.sentence[m.alignment.End]
+
 		m.totalKeystrokes++
-		
-		if expected == "\n" {
-			m.input += expected
+		m.keystrokes = append(m.keystrokes, Keystroke{
+			Index:    m.alignment.End,
+			Expected: expected,
+			Typed:    typed[0],
+			At:       time.Since(m.startTime),
+		})
+
+		if expected == '\n' {
+			m.input = append(m.input, expected)
 			m.correctKeystrokes++
-		} else if input != expected {
-			m.mistyped[rune(expected[0])]++
-			m.input += input
+		} else if typed[0] != expected {
+			m.mistyped[expected]++
+			m.confusions[confusionKey{expected, typed[0]}]++
+			m.input = append(m.input, typed[0])
 		} else {
-			m.input += input
+			m.input = append(m.input, typed[0])
 			m.correctKeystrokes++
 		}
-		
-		if len(m.input) == len(m.sentence) {
-			m.finished = true
+		m.aligner.push(m.input[len(m.input)-1])
+		m.alignment = m.aligner.alignment()
+
+		// Runaway input that never lines up with the text ends the race too.
+		if m.alignment.End == len(m.sentence) || len(m.input) >= 2*len(m.sentence) {
+			m.Finish()
 		}
 	}
 }
@@ -87,7 +192,14 @@ func (m *Model) HandleInput(input string) {
 func (m *Model) HandleBackspace() {
 	if len(m.input) > 0 {
 		m.input = m.input[:len(m.input)-1]
+		m.aligner.pop()
+		m.alignment = m.aligner.alignment()
 		m.totalKeystrokes++
+		m.keystrokes = append(m.keystrokes, Keystroke{
+			Index:     m.alignment.End,
+			At:        time.Since(m.startTime),
+			Backspace: true,
+		})
 	}
 }
 
@@ -95,12 +207,34 @@ func (m *Model) Restart() {
 	m.Init()
 }
 
+// Retry starts a race made of words, each repeated a few times in random
+// order, instead of fresh text. It does nothing without words.
+func (m *Model) Retry(words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	m.retry = retryText(words)
+	m.Init()
+	return true
+}
+
 type Stats struct {
-	Duration  time.Duration
-	Accuracy  float64
-	WPM       float64
-	Mistyped  []MistypedChar
-	Finished  bool
+	Duration   time.Duration  `json:"duration"`
+	Accuracy   float64        `json:"accuracy"`
+	WPM        float64        `json:"wpm"`
+	Mistyped   []MistypedChar `json:"mistyped,omitempty"`
+	Confusions []Confusion    `json:"confusions,omitempty"`
+	Text       string         `json:"text"`
+	Input      string         `json:"input"`
+	Keystrokes []Keystroke    `json:"keystrokes,omitempty"`
+	Errors     ErrorCounts    `json:"errors"`
+	Words      []WordStat     `json:"words,omitempty"`
+	// Retry marks a follow-up race made of the previous race's weak words.
+	Retry bool `json:"retry,omitempty"`
+	// Difficulty is the text's difficulty score on the layout typed.
+	Difficulty float64       `json:"difficulty,omitempty"`
+	Fingers    *FingerReport `json:"fingers,omitempty"`
+	Finished   bool          `json:"finished"`
 }
 
 type MistypedChar struct {
@@ -109,16 +243,39 @@ type MistypedChar struct {
 }
 
 func (m *Model) generateRandomSentence() string {
+	if m.retry != "" {
+		text := m.retry
+		m.retry = ""
+		return m.wrapText(text, 80)
+	}
+	return m.pickText(m.level)
+}
+
+// generateText makes up one text for the race from the source, a quote or
+// random words.
+func (m *Model) generateText() string {
+	if m.source != nil {
+		return m.wrapText(m.source(), 80)
+	}
+
+	if m.quote && len(m.pack.Quotes) > 0 {
+		return m.wrapText(m.pack.Quotes[rand.Intn(len(m.pack.Quotes))], 80)
+	}
+
 	if m.wordCount <= 0 {
 		m.wordCount = 20
 	}
-	
+
+	words := m.pack.Words
+	if m.pool != nil {
+		words = m.pool
+	}
 	var sentence []string
 	for i := 0; i < m.wordCount; i++ {
-		randomIndex := rand.Intn(len(words.Words))
-		sentence = append(sentence, words.Words[randomIndex])
+		randomIndex := rand.Intn(len(words))
+		sentence = append(sentence, words[randomIndex])
 	}
-	
+
 	fullSentence := strings.Join(sentence, " ")
 	return m.wrapText(fullSentence, 80)
 }
@@ -127,9 +284,9 @@ func (m *Model) wrapText(text string, maxWidth int) string {
 	words := strings.Fields(text)
 	var lines []string
 	currentLine := ""
-	
+
 	for _, word := range words {
-		if len(currentLine)+len(word)+1 <= maxWidth {
+		if utf8.RuneCountInString(currentLine)+utf8.RuneCountInString(word)+1 <= maxWidth {
 			if currentLine != "" {
 				currentLine += " " + word
 			} else {
@@ -145,7 +302,7 @@ func (m *Model) wrapText(text string, maxWidth int) string {
 	if currentLine != "" {
 		lines = append(lines, currentLine)
 	}
-	
+
 	return strings.Join(lines, "\n")
 }
 
@@ -153,7 +310,7 @@ func (m *Model) calculateAccuracy() float64 {
 	if m.totalKeystrokes == 0 {
 		return 0
 	}
-	
+
 	return float64(m.correctKeystrokes) / float64(m.totalKeystrokes) * 100
 }
 
@@ -165,7 +322,7 @@ func (m *Model) calculateWPM(duration time.Duration) float64 {
 	if minutes == 0 {
 		return 0
 	}
-	
+
 	return words / minutes
 }
 
@@ -183,7 +340,7 @@ func (m *Model) getTopMistyped(n int) []MistypedChar {
 		sorted = append(sorted, kv{k, v})
 	}
 	sort.Slice(sorted, func(i, j int) bool { return sorted[i].v > sorted[j].v })
-	
+
 	var result []MistypedChar
 	for i, pair := range sorted {
 		if i >= n {
@@ -194,7 +351,7 @@ func (m *Model) getTopMistyped(n int) []MistypedChar {
 			Count: pair.v,
 		})
 	}
-	
+
 	return result
 }
 
@@ -203,4 +360,4 @@ func min(a, b int) int {
 		return a
 	}
 	return b
-} 
\ No newline at end of file
+}
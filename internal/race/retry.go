@@ -0,0 +1,46 @@
+package race
+
+import (
+	"math/rand"
+	"strings"
+)
+
+const (
+	// retrySlowest is how many of the slowest words join the mistyped ones.
+	retrySlowest = 3
+	// retryRepeats is how often each word appears in the follow-up race.
+	retryRepeats = 3
+)
+
+// RetryWords picks the words of the race worth practising again: every
+// word with a mistake and the few typed slowest.
+func (s Stats) RetryWords() []string {
+	seen := make(map[string]bool)
+	var picked []string
+	add := func(word string) {
+		if word != "" && !seen[word] {
+			seen[word] = true
+			picked = append(picked, word)
+		}
+	}
+
+	for _, mistake := range s.Mistakes() {
+		add(mistake.Word)
+	}
+	for _, word := range s.SlowestWords(retrySlowest) {
+		add(word.Word)
+	}
+	return picked
+}
+
+// retryText repeats and shuffles words into the text of a follow-up race.
+func retryText(words []string) string {
+	var text []string
+	for _, word := range words {
+		for i := 0; i < retryRepeats; i++ {
+			text = append(text, word)
+		}
+	}
+	rand.Shuffle(len(text), func(i, j int) { text[i], text[j] = text[j], text[i] })
+	return strings.Join(text, " ")
+}
@@ -0,0 +1,49 @@
+package race
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRetryWords(t *testing.T) {
+	// timed sets the speed of each word of the text.
+	timed := func(stats Stats, speeds ...float64) Stats {
+		stats.Words = []WordStat{}
+		for i, word := range strings.Fields(stats.Text) {
+			stats.Words = append(stats.Words, WordStat{Word: word, WPM: speeds[i]})
+		}
+		return stats
+	}
+
+	tests := []struct {
+		name  string
+		stats Stats
+		want  []string
+	}{
+		{"clean", timed(typing("ab cd", "ab cd"), 50, 50), []string{"cd"}},
+		{"mistyped space", timed(typing("ab cd ef", "abxcd ef"), 50, 50, 50), []string{"ab", "cd", "ef"}},
+		{"mistyped letter", timed(typing("ab cd ef", "ab cx ef"), 50, 50, 50), []string{"cd", "ef"}},
+		{"no speeds", timed(typing("ab cd ef", "abxcd ef"), 0, 0, 0), []string{"ab"}},
+		{"mistyped leading space", timed(typing(" ab", "xab"), 0), nil},
+		{"slowest words after the first", timed(typing("ab cd ef gh", "ab cd ef gh"), 20, 40, 30, 60), []string{"ef", "cd", "gh"}},
+		{"mistakes first without repeats", timed(typing("ab cd ef gh", "ab cd ef gx"), 20, 40, 30, 60), []string{"gh", "ef", "cd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.RetryWords(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RetryWords = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryText(t *testing.T) {
+	got := strings.Fields(retryText([]string{"ab", "cd"}))
+	slices.Sort(got)
+	want := []string{"ab", "ab", "ab", "cd", "cd", "cd"}
+	if !slices.Equal(got, want) {
+		t.Errorf("retryText words = %q, want %q", got, want)
+	}
+}
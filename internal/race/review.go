@@ -0,0 +1,242 @@
+package race
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-typ0/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Mistake is one wrong key press with the word it happened in.
+type Mistake struct {
+	Keystroke Keystroke
+	// Word is the expected word and WordStart its position in the text.
+	Word      string
+	WordStart int
+	// Typed is what the word ended up as in the final input.
+	Typed string
+	// Time is how long the word took, from the key press before it to the
+	// last key press inside it.
+	Time      time.Duration
+	Corrected bool
+}
+
+// Mistakes lists every wrong key press of the race in the order they
+// happened. A mistake counts as corrected when the final input has the
+// right character in its place.
+func (s Stats) Mistakes() []Mistake {
+	text, input := []rune(s.Text), []rune(s.Input)
+	alignment := Align(text, input)
+
+	matched := make([]bool, len(text))
+	for _, edit := range alignment.Edits {
+		if edit.Op == OpMatch {
+			matched[edit.Text] = true
+		}
+	}
+
+	var mistakes []Mistake
+	for _, key := range s.Keystrokes {
+		if key.Backspace || key.Correct() || key.Index >= len(text) {
+			continue
+		}
+		start, end := wordBounds(text, key.Index)
+		mistakes = append(mistakes, Mistake{
+			Keystroke: key,
+			Word:      string(text[start:end]),
+			WordStart: start,
+			Typed:     typedWord(alignment, input, start, end),
+			Time:      wordTime(s.Keystrokes, start, end),
+			Corrected: matched[key.Index],
+		})
+	}
+	return mistakes
+}
+
+// wordBounds finds the word around text position i. A mistake on a space
+// belongs to the word before it.
+func wordBounds(text []rune, i int) (int, int) {
+	start, end := i, i
+	for start > 0 && !unicode.IsSpace(text[start-1]) {
+		start--
+	}
+	if unicode.IsSpace(text[i]) {
+		return start, end
+	}
+	for end < len(text) && !unicode.IsSpace(text[end]) {
+		end++
+	}
+	return start, end
+}
+
+// typedWord collects the input aligned with text[start:end], including
+// extra characters typed inside it.
+func typedWord(alignment Alignment, input []rune, start, end int) string {
+	var typed []rune
+	last := -1
+	for _, edit := range alignment.Edits {
+		if edit.Text >= 0 {
+			last = edit.Text
+			if edit.Op == OpTransposition {
+				last++
+			}
+		}
+		inWord := (edit.Text >= start && edit.Text < end) || (edit.Text < 0 && last >= start && last < end-1)
+		if !inWord || edit.Input < 0 {
+			continue
+		}
+		typed = append(typed, input[edit.Input])
+		if edit.Op == OpTransposition {
+			typed = append(typed, input[edit.Input+1])
+		}
+	}
+	return string(typed)
+}
+
+func wordTime(keystrokes []Keystroke, start, end int) time.Duration {
+	var (
+		from, to time.Duration
+		found    bool
+	)
+	for i, key := range keystrokes {
+		if key.Index < start || key.Index >= end {
+			continue
+		}
+		if !found {
+			found = true
+			if i > 0 {
+				from = keystrokes[i-1].At
+			}
+		}
+		to = key.At
+	}
+	return to - from
+}
+
+// Review steps through the mistakes of a finished race, one at a time.
+type Review struct {
+	stats    Stats
+	mistakes []Mistake
+	current  int
+	closed   bool
+	styles   *ui.Styles
+}
+
+func NewReview(stats Stats) *Review {
+	return &Review{
+		stats:    stats,
+		mistakes: stats.Mistakes(),
+		styles:   ui.NewStyles(),
+	}
+}
+
+func (r *Review) Init() tea.Cmd {
+	return nil
+}
+
+// Closed reports whether the review was left to go back to the results.
+func (r *Review) Closed() bool {
+	return r.closed
+}
+
+func (r *Review) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return r, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c":
+		return r, tea.Quit
+	case "esc", "q", "e":
+		r.closed = true
+	case "right", "l", "n", " ", "tab":
+		if r.current < len(r.mistakes)-1 {
+			r.current++
+		}
+	case "left", "h", "p", "shift+tab":
+		if r.current > 0 {
+			r.current--
+		}
+	case "home":
+		r.current = 0
+	case "end":
+		r.current = max(len(r.mistakes)-1, 0)
+	}
+	return r, nil
+}
+
+func (r *Review) View() string {
+	if len(r.mistakes) == 0 {
+		return r.styles.StatsBoxStyle.Render("No mistakes to review.\n\n" + r.styles.LabelStyle.Render("ESC to go back"))
+	}
+
+	mistake := r.mistakes[r.current]
+	key := mistake.Keystroke
+
+	status := r.styles.RedStyle.Render("not corrected")
+	if mistake.Corrected {
+		status = r.styles.GreenStyle.Render("corrected")
+	}
+
+	lines := []string{
+		r.styles.LabelStyle.Render(fmt.Sprintf("Mistake %d of %d", r.current+1, len(r.mistakes))),
+		"",
+		r.renderContext(mistake),
+		"",
+		fmt.Sprintf("%s %s", r.styles.LabelStyle.Render("Expected:"), r.styles.ValueStyle.Render(mistake.Word)),
+		fmt.Sprintf("%s %s", r.styles.LabelStyle.Render("Typed:   "), r.renderTyped(mistake)),
+		fmt.Sprintf("%s %s for %s at %.1fs",
+			r.styles.LabelStyle.Render("Pressed: "),
+			r.styles.MistypedKeyStyle.Render(fmt.Sprintf("%q", key.Typed)),
+			r.styles.ValueStyle.Render(fmt.Sprintf("%q", key.Expected)),
+			key.At.Seconds()),
+		fmt.Sprintf("%s %s", r.styles.LabelStyle.Render("Time:    "), r.styles.ValueStyle.Render(fmt.Sprintf("%.2fs on the word", mistake.Time.Seconds()))),
+		fmt.Sprintf("%s %s", r.styles.LabelStyle.Render("Status:  "), status),
+		"",
+		r.styles.LabelStyle.Render("←/→ to step through mistakes. ESC to go back"),
+	}
+	return r.styles.StatsBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderContext shows the line of text the mistake is on, with the word
+// underlined and the mistaken character in red.
+func (r *Review) renderContext(mistake Mistake) string {
+	text := []rune(r.stats.Text)
+	start, end := mistake.WordStart, mistake.Keystroke.Index
+	for start > 0 && text[start-1] != '\n' {
+		start--
+	}
+	for end < len(text) && text[end] != '\n' {
+		end++
+	}
+
+	wordEnd := mistake.WordStart + len([]rune(mistake.Word))
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		char := string(text[i])
+		switch {
+		case i == mistake.Keystroke.Index:
+			b.WriteString(r.styles.MistypedKeyStyle.Underline(true).Render(char))
+		case i >= mistake.WordStart && i < wordEnd:
+			b.WriteString(r.styles.UnderlineStyle.Render(char))
+		default:
+			b.WriteString(r.styles.DimStyle.Render(char))
+		}
+	}
+	return b.String()
+}
+
+func (r *Review) renderTyped(mistake Mistake) string {
+	if mistake.Typed == mistake.Word {
+		return r.styles.GreenStyle.Render(mistake.Typed)
+	}
+	if mistake.Typed == "" {
+		return r.styles.RedStyle.Render("(nothing)")
+	}
+	return r.styles.RedStyle.Render(mistake.Typed)
+}
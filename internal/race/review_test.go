@@ -0,0 +1,97 @@
+package race
+
+import (
+	"testing"
+	"time"
+)
+
+// typing records input typed over text one key every tenth of a second,
+// with '\b' as backspace.
+func typing(text, input string) Stats {
+	expected := []rune(text)
+	var (
+		keys  []Keystroke
+		typed []rune
+	)
+	for _, r := range input {
+		at := time.Duration(len(keys)+1) * 100 * time.Millisecond
+		if r == '\b' {
+			typed = typed[:len(typed)-1]
+			keys = append(keys, Keystroke{Index: len(typed), At: at, Backspace: true})
+			continue
+		}
+		key := Keystroke{Index: len(typed), Typed: r, At: at}
+		if len(typed) < len(expected) {
+			key.Expected = expected[len(typed)]
+		}
+		keys = append(keys, key)
+		typed = append(typed, r)
+	}
+	return Stats{Text: text, Input: string(typed), Keystrokes: keys, Finished: true}
+}
+
+func TestWordBounds(t *testing.T) {
+	tests := []struct {
+		text       string
+		i          int
+		start, end int
+	}{
+		{"ab cd ef", 0, 0, 2},
+		{"ab cd ef", 1, 0, 2},
+		{"ab cd ef", 2, 0, 2},
+		{"ab cd ef", 3, 3, 5},
+		{"ab cd ef", 5, 3, 5},
+		{"ab cd ef", 7, 6, 8},
+		{"ab\ncd", 2, 0, 2},
+		{" ab", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		start, end := wordBounds([]rune(tt.text), tt.i)
+		if start != tt.start || end != tt.end {
+			t.Errorf("wordBounds(%q, %d) = %d, %d, want %d, %d", tt.text, tt.i, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestMistakes(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  []Mistake
+	}{
+		{"none", typing("ab cd ef", "ab cd ef"), nil},
+		{
+			name:  "space",
+			stats: typing("ab cd ef", "abxcd ef"),
+			want:  []Mistake{{Word: "ab", WordStart: 0, Typed: "ab", Time: 200 * time.Millisecond}},
+		},
+		{
+			name:  "corrected",
+			stats: typing("ab cd ef", "ab x\bcd ef"),
+			want:  []Mistake{{Word: "cd", WordStart: 3, Typed: "cd", Time: 400 * time.Millisecond, Corrected: true}},
+		},
+		{
+			name:  "last word",
+			stats: typing("ab cd ef", "ab cd eg"),
+			want:  []Mistake{{Word: "ef", WordStart: 6, Typed: "eg", Time: 200 * time.Millisecond}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stats.Mistakes()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d mistakes, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				mistake := got[i]
+				if mistake.Keystroke.Correct() {
+					t.Errorf("mistake %d is a correct key press", i)
+				}
+				mistake.Keystroke = Keystroke{}
+				if mistake != want {
+					t.Errorf("mistake %d = %+v, want %+v", i, mistake, want)
+				}
+			}
+		})
+	}
+}
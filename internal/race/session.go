@@ -0,0 +1,285 @@
+package race
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go-typ0/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// interstitial is the pause between the rounds of a session.
+	interstitial  = 3 * time.Second
+	shownMistypes = 5
+)
+
+// SessionSummary adds up the rounds of a session.
+type SessionSummary struct {
+	Rounds       []Stats
+	MeanWPM      float64
+	MedianWPM    float64
+	BestWPM      float64
+	BestRound    int
+	MeanAccuracy float64
+	Duration     time.Duration
+	Mistyped     []MistypedChar
+	Confusions   []Confusion
+}
+
+func Summarize(rounds []Stats) SessionSummary {
+	s := SessionSummary{Rounds: rounds}
+	if len(rounds) == 0 {
+		return s
+	}
+	s.BestWPM, s.BestRound = rounds[0].WPM, 1
+
+	wpms := make([]float64, len(rounds))
+	mistyped := make(map[rune]int)
+	for i, stats := range rounds {
+		wpms[i] = stats.WPM
+		s.MeanWPM += stats.WPM
+		s.MeanAccuracy += stats.Accuracy
+		s.Duration += stats.Duration
+		if stats.WPM > s.BestWPM {
+			s.BestWPM, s.BestRound = stats.WPM, i+1
+		}
+		for r, key := range stats.KeyStats() {
+			if key.Errors > 0 {
+				mistyped[r] += key.Errors
+			}
+		}
+	}
+	s.MeanWPM /= float64(len(rounds))
+	s.MeanAccuracy /= float64(len(rounds))
+
+	sort.Float64s(wpms)
+	s.MedianWPM = wpms[len(wpms)/2]
+	if len(wpms)%2 == 0 {
+		s.MedianWPM = (wpms[len(wpms)/2-1] + wpms[len(wpms)/2]) / 2
+	}
+
+	for r, count := range mistyped {
+		s.Mistyped = append(s.Mistyped, MistypedChar{Char: r, Count: count})
+	}
+	sort.Slice(s.Mistyped, func(i, j int) bool {
+		if s.Mistyped[i].Count != s.Mistyped[j].Count {
+			return s.Mistyped[i].Count > s.Mistyped[j].Count
+		}
+		return s.Mistyped[i].Char < s.Mistyped[j].Char
+	})
+	s.Confusions = MergeConfusions(rounds)
+	return s
+}
+
+type sessionPhase int
+
+const (
+	sessionRace sessionPhase = iota
+	sessionBreak
+	sessionDone
+)
+
+type breakTick struct {
+	round int
+}
+
+// Session runs several races back to back with a short break between
+// them, and sums them up at the end instead of showing each race's results.
+type Session struct {
+	model  *Model
+	race   *ViewModel
+	total  int
+	rounds []Stats
+	phase  sessionPhase
+	// next is when the break ends.
+	next time.Time
+
+	width  int
+	height int
+	styles *ui.Styles
+}
+
+// NewSession runs rounds races with vm, which should already have its
+// recorders attached so every round is saved.
+func NewSession(vm *ViewModel, rounds int) *Session {
+	s := &Session{
+		model:  vm.model,
+		race:   vm,
+		total:  rounds,
+		styles: ui.NewStyles(),
+	}
+	vm.DisableRetry()
+	vm.OnFinish(func(stats Stats) {
+		s.rounds = append(s.rounds, stats)
+	})
+	vm.SetPanel(func() string {
+		if s.phase != sessionRace {
+			return ""
+		}
+		return s.styles.LabelStyle.Render(fmt.Sprintf("Round %d of %d", len(s.rounds)+1, s.total))
+	})
+	return s
+}
+
+func (s *Session) Init() tea.Cmd {
+	return s.race.Init()
+}
+
+func (s *Session) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width, s.height = msg.Width, msg.Height
+		s.race.Update(msg)
+		return s, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return s, tea.Quit
+		}
+	case breakTick:
+		if s.phase == sessionBreak && msg.round == len(s.rounds) {
+			if time.Now().Before(s.next) {
+				return s, s.tick()
+			}
+			s.startRound()
+		}
+		return s, nil
+	}
+
+	switch s.phase {
+	case sessionRace:
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEsc {
+			return s, s.end()
+		}
+		_, cmd := s.race.Update(msg)
+		if !s.model.Finished() {
+			return s, cmd
+		}
+		if len(s.rounds) >= s.total {
+			s.phase = sessionDone
+			return s, nil
+		}
+		s.phase = sessionBreak
+		s.next = time.Now().Add(interstitial)
+		return s, s.tick()
+	case sessionBreak:
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.Type {
+			case tea.KeyEsc:
+				return s, s.end()
+			case tea.KeyEnter, tea.KeySpace:
+				s.startRound()
+			}
+		}
+	case sessionDone:
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Type == tea.KeyEnter:
+				s.rounds = nil
+				s.startRound()
+			case key.Type == tea.KeyEsc, key.String() == "q":
+				return s, tea.Quit
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *Session) tick() tea.Cmd {
+	round := len(s.rounds)
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return breakTick{round: round}
+	})
+}
+
+func (s *Session) startRound() {
+	s.model.Restart()
+	s.phase = sessionRace
+}
+
+// end stops the session early, summing up the rounds finished so far.
+func (s *Session) end() tea.Cmd {
+	if len(s.rounds) == 0 {
+		return tea.Quit
+	}
+	s.phase = sessionDone
+	return nil
+}
+
+func (s *Session) View() string {
+	var content string
+	switch s.phase {
+	case sessionRace:
+		return s.race.View()
+	case sessionBreak:
+		content = s.renderBreak()
+	case sessionDone:
+		content = s.renderSummary()
+	}
+
+	if s.width > 0 && s.height > 0 {
+		return lipgloss.Place(s.width, s.height, lipgloss.Center, lipgloss.Center, content)
+	}
+	return content
+}
+
+func (s *Session) renderBreak() string {
+	last := s.rounds[len(s.rounds)-1]
+	lines := []string{
+		s.styles.LabelStyle.Render(fmt.Sprintf("Round %d of %d done", len(s.rounds), s.total)),
+		"",
+		fmt.Sprintf("%s %s", s.styles.LabelStyle.Render("WPM:"), s.styles.ValueStyle.Render(fmt.Sprintf("%.2f", last.WPM))),
+		fmt.Sprintf("%s %s", s.styles.LabelStyle.Render("Accuracy:"), s.styles.ValueStyle.Render(fmt.Sprintf("%.2f%%", last.Accuracy))),
+	}
+	lines = append(lines, s.race.Notices()...)
+
+	left := math.Ceil(max(time.Until(s.next).Seconds(), 0))
+	lines = append(lines, "",
+		s.styles.LabelStyle.Render(fmt.Sprintf("Next round in %.0fs. Enter to start now, ESC to stop here", left)))
+	return s.styles.StatsBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (s *Session) renderSummary() string {
+	summary := Summarize(s.rounds)
+	value := func(label, v string) string {
+		return fmt.Sprintf("%s %s", s.styles.LabelStyle.Render(label), s.styles.ValueStyle.Render(v))
+	}
+
+	title := fmt.Sprintf("Session: %d rounds", len(s.rounds))
+	if len(s.rounds) < s.total {
+		title = fmt.Sprintf("Session: %d of %d rounds", len(s.rounds), s.total)
+	}
+	lines := []string{
+		s.styles.ValueStyle.Render(title),
+		"",
+		value("Time:", fmt.Sprintf("%.2f seconds", summary.Duration.Seconds())),
+		value("WPM:", fmt.Sprintf("%.2f mean, %.2f median, %.2f best (round %d)",
+			summary.MeanWPM, summary.MedianWPM, summary.BestWPM, summary.BestRound)),
+		value("Accuracy:", fmt.Sprintf("%.2f%% mean", summary.MeanAccuracy)),
+		"",
+		s.styles.LabelStyle.Render(fmt.Sprintf("%-7s %8s %9s %8s", "Round", "WPM", "Accuracy", "Time")),
+	}
+	for i, round := range summary.Rounds {
+		row := fmt.Sprintf("%-7d %8.2f %8.2f%% %7.1fs", i+1, round.WPM, round.Accuracy, round.Duration.Seconds())
+		if i+1 == summary.BestRound {
+			row = s.styles.GreenStyle.Render(row)
+		}
+		lines = append(lines, row)
+	}
+
+	combined := Stats{Mistyped: summary.Mistyped, Confusions: summary.Confusions}
+	if len(combined.Mistyped) > shownMistypes {
+		combined.Mistyped = combined.Mistyped[:shownMistypes]
+	}
+	if mistypes := s.race.renderMistypes(combined); mistypes != "" {
+		lines = append(lines, "", strings.TrimRight(mistypes, " \n"))
+	}
+
+	lines = append(lines, "", s.styles.LabelStyle.Render("Press Enter for another session. ESC/CTRL+C/Q to quit"))
+	return s.styles.StatsBoxStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,83 @@
+package race
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	// typed returns keystrokes for text, mistyping the given positions as x.
+	typed := func(text string, wrong ...int) []Keystroke {
+		var keys []Keystroke
+		for i, r := range []rune(text) {
+			key := Keystroke{Index: i, Expected: r, Typed: r, At: time.Duration(i+1) * 200 * time.Millisecond}
+			for _, w := range wrong {
+				if w == i {
+					key.Typed = 'x'
+				}
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name   string
+		rounds []Stats
+		want   SessionSummary
+	}{
+		{
+			name: "none",
+			want: SessionSummary{},
+		},
+		{
+			name: "odd",
+			rounds: []Stats{
+				{WPM: 50, Accuracy: 90, Duration: 10 * time.Second, Keystrokes: typed("abc", 0),
+					Confusions: []Confusion{{Expected: 'a', Typed: 'x', Count: 1}}},
+				{WPM: 70, Accuracy: 100, Duration: 8 * time.Second, Keystrokes: typed("abc")},
+				{WPM: 60, Accuracy: 80, Duration: 9 * time.Second, Keystrokes: typed("abc", 0, 2),
+					Confusions: []Confusion{{Expected: 'a', Typed: 'x', Count: 1}, {Expected: 'c', Typed: 'x', Count: 1}}},
+			},
+			want: SessionSummary{
+				MeanWPM:      60,
+				MedianWPM:    60,
+				BestWPM:      70,
+				BestRound:    2,
+				MeanAccuracy: 90,
+				Duration:     27 * time.Second,
+				Mistyped:     []MistypedChar{{Char: 'a', Count: 2}, {Char: 'c', Count: 1}},
+				Confusions:   []Confusion{{Expected: 'a', Typed: 'x', Count: 2}, {Expected: 'c', Typed: 'x', Count: 1}},
+			},
+		},
+		{
+			name: "even",
+			rounds: []Stats{
+				{WPM: 40, Accuracy: 100},
+				{WPM: 80, Accuracy: 100},
+				{WPM: 50, Accuracy: 100},
+				{WPM: 60, Accuracy: 100},
+			},
+			want: SessionSummary{MeanWPM: 57.5, MedianWPM: 55, BestWPM: 80, BestRound: 2, MeanAccuracy: 100},
+		},
+		{
+			name:   "no speed",
+			rounds: []Stats{{}, {}},
+			want:   SessionSummary{BestRound: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.rounds)
+			tt.want.Rounds = tt.rounds
+			// Without mistakes the confusions may be empty rather than nil.
+			if len(got.Confusions) == 0 && len(tt.want.Confusions) == 0 {
+				got.Confusions, tt.want.Confusions = nil, nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Summarize =\n%+v, want\n%+v", got, tt.want)
+			}
+		})
+	}
+}
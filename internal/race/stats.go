@@ -0,0 +1,175 @@
+package race
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// maxLatency drops pauses from per-key speed figures.
+const maxLatency = 2 * time.Second
+
+// Keystroke is one key press during a race. Backspaces have a zero Typed
+// and Expected rune.
+type Keystroke struct {
+	Index     int
+	Expected  rune
+	Typed     rune
+	At        time.Duration
+	Backspace bool
+}
+
+func (k Keystroke) Correct() bool {
+	return !k.Backspace && (k.Typed == k.Expected || k.Expected == '\n')
+}
+
+type keystrokeJSON struct {
+	Index     int           `json:"index"`
+	Expected  string        `json:"expected,omitempty"`
+	Typed     string        `json:"typed,omitempty"`
+	At        time.Duration `json:"at"`
+	Backspace bool          `json:"backspace,omitempty"`
+}
+
+func (k Keystroke) MarshalJSON() ([]byte, error) {
+	return json.Marshal(keystrokeJSON{
+		Index:     k.Index,
+		Expected:  runeString(k.Expected),
+		Typed:     runeString(k.Typed),
+		At:        k.At,
+		Backspace: k.Backspace,
+	})
+}
+
+func (k *Keystroke) UnmarshalJSON(data []byte) error {
+	var raw keystrokeJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*k = Keystroke{
+		Index:     raw.Index,
+		Expected:  firstRune(raw.Expected),
+		Typed:     firstRune(raw.Typed),
+		At:        raw.At,
+		Backspace: raw.Backspace,
+	}
+	return nil
+}
+
+func (m MistypedChar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Char  string `json:"char"`
+		Count int    `json:"count"`
+	}{runeString(m.Char), m.Count})
+}
+
+func (m *MistypedChar) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Char  string `json:"char"`
+		Count int    `json:"count"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = MistypedChar{Char: firstRune(raw.Char), Count: raw.Count}
+	return nil
+}
+
+func runeString(r rune) string {
+	if r == 0 {
+		return ""
+	}
+	return string(r)
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// KeyStat sums up the presses of one expected character. Latency only
+// covers correct presses that were not preceded by a pause.
+type KeyStat struct {
+	Presses int           `json:"presses"`
+	Errors  int           `json:"errors"`
+	Latency time.Duration `json:"latency"`
+	Timed   int           `json:"timed"`
+}
+
+func (k KeyStat) ErrorRate() float64 {
+	if k.Presses == 0 {
+		return 0
+	}
+	return float64(k.Errors) / float64(k.Presses)
+}
+
+func (k KeyStat) AverageLatency() time.Duration {
+	if k.Timed == 0 {
+		return 0
+	}
+	return k.Latency / time.Duration(k.Timed)
+}
+
+func (k *KeyStat) Add(other KeyStat) {
+	k.Presses += other.Presses
+	k.Errors += other.Errors
+	k.Latency += other.Latency
+	k.Timed += other.Timed
+}
+
+// KeyStats breaks the race down by expected character. The latency of a
+// press is the time since the previous keystroke.
+func (s Stats) KeyStats() map[rune]KeyStat {
+	keys := make(map[rune]KeyStat)
+
+	var prev time.Duration
+	for i, key := range s.Keystrokes {
+		latency := key.At - prev
+		prev = key.At
+		if key.Backspace || key.Expected == '\n' {
+			continue
+		}
+
+		stat := keys[key.Expected]
+		stat.Presses++
+		if !key.Correct() {
+			stat.Errors++
+		} else if i > 0 && latency <= maxLatency {
+			stat.Latency += latency
+			stat.Timed++
+		}
+		keys[key.Expected] = stat
+	}
+	return keys
+}
+
+// MergeKeyStats adds up the per-key figures of several races.
+func MergeKeyStats(races []Stats) map[rune]KeyStat {
+	keys := make(map[rune]KeyStat)
+	for _, stats := range races {
+		for r, stat := range stats.KeyStats() {
+			total := keys[r]
+			total.Add(stat)
+			keys[r] = total
+		}
+	}
+	return keys
+}
+
+// Complete reports whether the whole text was typed, rather than the race
+// being ended early.
+func (s Stats) Complete() bool {
+	text := []rune(s.Text)
+	return len(text) > 0 && Align(text, []rune(s.Input)).End == len(text)
+}
+
+// Comparable reports whether the race belongs in averages and comparisons:
+// it is not a retry and it went to the end, either typed through or, for
+// races imported without their text, not abandoned.
+func (s Stats) Comparable() bool {
+	if s.Retry || !s.Finished {
+		return false
+	}
+	return s.Text == "" || s.Complete()
+}
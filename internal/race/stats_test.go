@@ -0,0 +1,23 @@
+package race
+
+import "testing"
+
+func TestComparable(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  bool
+	}{
+		{"typed to the end", Stats{Text: "ab cd", Input: "ab cd", Finished: true}, true},
+		{"with mistakes", Stats{Text: "ab cd", Input: "ax cd", Finished: true}, true},
+		{"ended early", Stats{Text: "ab cd", Input: "ab", Finished: true}, false},
+		{"retry", Stats{Text: "ab cd", Input: "ab cd", Finished: true, Retry: true}, false},
+		{"imported without text", Stats{WPM: 50, Finished: true}, true},
+		{"imported and abandoned", Stats{WPM: 50}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.stats.Comparable(); got != tt.want {
+			t.Errorf("%s: Comparable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
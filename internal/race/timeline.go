@@ -0,0 +1,188 @@
+package race
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	chartHeight = 5
+	// minChartWidth is the narrowest plot worth drawing as a chart; below
+	// it the results show a one-line sparkline.
+	minChartWidth = 20
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sample is one second of a race. WPM counts correct characters since the
+// start; Raw counts every key pressed during that second alone.
+type Sample struct {
+	Second int
+	WPM    float64
+	Raw    float64
+	Errors int
+}
+
+// Timeline samples the race every second.
+func (s Stats) Timeline() []Sample {
+	if s.Duration <= 0 {
+		return nil
+	}
+
+	seconds := int(math.Ceil(s.Duration.Seconds()))
+	samples := make([]Sample, seconds)
+	correct := 0
+	k := 0
+	for i := range samples {
+		from := time.Duration(i) * time.Second
+		to := from + time.Second
+		if to > s.Duration {
+			to = s.Duration
+		}
+
+		pressed := 0
+		for ; k < len(s.Keystrokes) && s.Keystrokes[k].At < to; k++ {
+			key := s.Keystrokes[k]
+			if key.Backspace {
+				continue
+			}
+			pressed++
+			if key.Correct() {
+				correct++
+			} else {
+				samples[i].Errors++
+			}
+		}
+
+		samples[i].Second = i + 1
+		samples[i].WPM = float64(correct) / 5 / to.Minutes()
+		if span := (to - from).Minutes(); span > 0 {
+			samples[i].Raw = float64(pressed) / 5 / span
+		}
+	}
+	return samples
+}
+
+// bucket averages samples down to at most n columns.
+func bucket(samples []Sample, n int) ([]Sample, int) {
+	per := (len(samples) + n - 1) / n
+	if per <= 1 {
+		return samples, 1
+	}
+
+	var buckets []Sample
+	for i := 0; i < len(samples); i += per {
+		group := samples[i:min(i+per, len(samples))]
+		b := Sample{Second: group[len(group)-1].Second}
+		for _, s := range group {
+			b.WPM += s.WPM
+			b.Raw += s.Raw
+			b.Errors += s.Errors
+		}
+		b.WPM /= float64(len(group))
+		b.Raw /= float64(len(group))
+		buckets = append(buckets, b)
+	}
+	return buckets, per
+}
+
+// renderChart plots WPM and raw WPM over the race with a row marking the
+// seconds that had errors. Series differ by glyph as well as colour so the
+// chart still reads without colours; narrow terminals get a sparkline.
+func (vm *ViewModel) renderChart(stats Stats) string {
+	samples := stats.Timeline()
+	if len(samples) < 2 {
+		return ""
+	}
+
+	// Room left inside the stats box border and padding, less the axis.
+	width := 60
+	if vm.model.width > 0 {
+		width = min(width, vm.model.width-12)
+	}
+	if width < minChartWidth {
+		return vm.renderSparkline(samples, max(width+4, 8))
+	}
+
+	samples, per := bucket(samples, width)
+	top := 0.0
+	for _, s := range samples {
+		top = math.Max(top, math.Max(s.WPM, s.Raw))
+	}
+	top = math.Max(10, math.Ceil(top/10)*10)
+	level := func(v float64) int {
+		return int(math.Round(v / top * (chartHeight - 1)))
+	}
+
+	var lines []string
+	for row := chartHeight - 1; row >= 0; row-- {
+		axis := "    "
+		switch row {
+		case chartHeight - 1:
+			axis = fmt.Sprintf("%3.0f ", top)
+		case 0:
+			axis = "  0 "
+		}
+
+		var b strings.Builder
+		b.WriteString(vm.styles.DimStyle.Render(axis + "│"))
+		for _, s := range samples {
+			switch {
+			case level(s.WPM) == row:
+				b.WriteString(vm.styles.ValueStyle.Render("•"))
+			case level(s.Raw) == row:
+				b.WriteString(vm.styles.DimStyle.Render("·"))
+			default:
+				b.WriteString(" ")
+			}
+		}
+		lines = append(lines, b.String())
+	}
+
+	var errs strings.Builder
+	errs.WriteString(vm.styles.DimStyle.Render("    └"))
+	for _, s := range samples {
+		if s.Errors > 0 {
+			errs.WriteString(vm.styles.RedStyle.Render("x"))
+		} else {
+			errs.WriteString(vm.styles.DimStyle.Render("─"))
+		}
+	}
+	lines = append(lines, errs.String())
+
+	legend := fmt.Sprintf("%s wpm  %s raw  %s errors", vm.styles.ValueStyle.Render("•"), vm.styles.DimStyle.Render("·"), vm.styles.RedStyle.Render("x"))
+	if per > 1 {
+		legend += fmt.Sprintf("  (%ds per column)", per)
+	}
+	lines = append(lines, "     "+legend)
+	return vm.styles.LabelStyle.Render("Speed:") + "\n" + strings.Join(lines, "\n")
+}
+
+// renderSparkline squeezes the WPM curve into one line, scaled from the
+// slowest to the fastest moment, with errors marked on the line below.
+func (vm *ViewModel) renderSparkline(samples []Sample, width int) string {
+	samples, _ = bucket(samples, width)
+	low, high := math.Inf(1), 0.0
+	for _, s := range samples {
+		low, high = math.Min(low, s.WPM), math.Max(high, s.WPM)
+	}
+
+	var line, errs strings.Builder
+	for _, s := range samples {
+		i := 0
+		if high > low {
+			i = int(math.Round((s.WPM - low) / (high - low) * float64(len(sparks)-1)))
+		}
+		line.WriteRune(sparks[i])
+		if s.Errors > 0 {
+			errs.WriteString("x")
+		} else {
+			errs.WriteString(" ")
+		}
+	}
+	return vm.styles.LabelStyle.Render("Speed:") + "\n" +
+		vm.styles.ValueStyle.Render(line.String()) + "\n" +
+		vm.styles.RedStyle.Render(strings.TrimRight(errs.String(), " "))
+}
@@ -12,8 +12,19 @@ import (
 )
 
 type ViewModel struct {
-	model  *Model
-	styles *ui.Styles
+	model    *Model
+	styles   *ui.Styles
+	onFinish []func(Stats)
+	panel    func() string
+	history  func() []Stats
+	keys     func() map[rune]KeyStat
+	words    func() map[string]WordTotal
+	review   *Review
+	noRetry  bool
+	notices  []func() string
+
+	showHistory bool
+	showLatency bool
 }
 
 func NewViewModel(model *Model) *ViewModel {
@@ -23,6 +34,48 @@ func NewViewModel(model *Model) *ViewModel {
 	}
 }
 
+// OnFinish registers fn to be called once with the stats of every race
+// that finishes. Functions run in the order they were registered.
+func (vm *ViewModel) OnFinish(fn func(Stats)) {
+	vm.onFinish = append(vm.onFinish, fn)
+}
+
+// SetHistory registers fn to provide the stats of past races for the
+// results screen.
+func (vm *ViewModel) SetHistory(fn func() []Stats) {
+	vm.history = fn
+}
+
+// SetKeyTotals registers fn to provide the per-key figures of every past
+// race, saving the heatmaps from adding them up from the history.
+func (vm *ViewModel) SetKeyTotals(fn func() map[rune]KeyStat) {
+	vm.keys = fn
+}
+
+// SetWordTotals registers fn to provide how every word has been typed
+// across past races, saving the problem words from adding them up from
+// the history.
+func (vm *ViewModel) SetWordTotals(fn func() map[string]WordTotal) {
+	vm.words = fn
+}
+
+// DisableRetry removes the option to follow a race with one made of its
+// mistyped and slowest words, for callers that judge every race's text.
+func (vm *ViewModel) DisableRetry() {
+	vm.noRetry = true
+}
+
+// AddNotice registers fn to render a line at the top of the results, such
+// as a new personal best. Empty lines are left out.
+func (vm *ViewModel) AddNotice(fn func() string) {
+	vm.notices = append(vm.notices, fn)
+}
+
+// SetPanel registers fn to render extra content below the race.
+func (vm *ViewModel) SetPanel(fn func() string) {
+	vm.panel = fn
+}
+
 func (vm *ViewModel) Init() tea.Cmd {
 	vm.model.Init()
 	return tea.EnterAltScreen
@@ -36,6 +89,14 @@ func (vm *ViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return vm, nil
 	}
 
+	if vm.review != nil {
+		_, cmd := vm.review.Update(msg)
+		if vm.review.Closed() {
+			vm.review = nil
+		}
+		return vm, cmd
+	}
+
 	if vm.model.finished {
 		if key, ok := msg.(tea.KeyMsg); ok {
 			switch key.Type {
@@ -45,6 +106,18 @@ func (vm *ViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(key.Runes) == 1 && key.Runes[0] == 'q' {
 					return vm, tea.Quit
 				}
+				if len(key.Runes) == 1 && key.Runes[0] == 'h' && vm.history != nil {
+					vm.showHistory = !vm.showHistory
+				}
+				if len(key.Runes) == 1 && key.Runes[0] == 'm' {
+					vm.showLatency = !vm.showLatency
+				}
+				if len(key.Runes) == 1 && key.Runes[0] == 'e' {
+					vm.review = NewReview(vm.model.GetStats())
+				}
+				if len(key.Runes) == 1 && key.Runes[0] == 'r' && !vm.noRetry {
+					vm.model.Retry(vm.model.GetStats().RetryWords())
+				}
 			case tea.KeyEnter:
 				vm.model.Restart()
 				return vm, nil
@@ -59,33 +132,88 @@ func (vm *ViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			return vm, tea.Quit
 		case tea.KeyEnter:
-			vm.model.finished = true
-			return vm, nil
+			vm.model.Finish()
 		case tea.KeyBackspace:
 			vm.model.HandleBackspace()
 		default:
-			vm.model.HandleInput(msg.String())
+			vm.model.HandleInput(vm.remap(msg))
+		}
+	}
+
+	if vm.model.finished {
+		stats := vm.model.GetStats()
+		for _, fn := range vm.onFinish {
+			fn(stats)
 		}
 	}
 
 	return vm, nil
 }
 
+// renderMistypes lists the keys mistyped most, beside what was typed
+// instead of them.
+func (vm *ViewModel) renderMistypes(stats Stats) string {
+	if len(stats.Mistyped) == 0 {
+		return ""
+	}
+
+	mistypedStr := ""
+	for _, mistyped := range stats.Mistyped {
+		mistypedStr += fmt.Sprintf("- %s %s\n",
+			vm.styles.MistypedKeyStyle.Render(fmt.Sprintf("%q", mistyped.Char)),
+			vm.styles.ValueStyle.Render(fmt.Sprintf("%d", mistyped.Count)))
+	}
+	mistypes := vm.styles.LabelStyle.Render("Mistypes: ") + "\n" + mistypedStr
+	if confusions := vm.renderConfusions(stats); confusions != "" {
+		mistypes = lipgloss.JoinHorizontal(lipgloss.Top, mistypes, "    ", confusions)
+	}
+	return mistypes
+}
+
+// remap translates typed runes into the emulated layout, if any.
+func (vm *ViewModel) remap(msg tea.KeyMsg) string {
+	if vm.model.layout == nil || msg.Type != tea.KeyRunes || msg.Alt {
+		return msg.String()
+	}
+
+	runes := make([]rune, len(msg.Runes))
+	for i, r := range msg.Runes {
+		runes[i] = vm.model.layout.Remap(r)
+	}
+	return string(runes)
+}
+
 func (vm *ViewModel) View() string {
-	sentenceView := vm.renderSentence(vm.model.sentence, vm.model.input)
-	contentWidth := lipgloss.Width(vm.model.sentence) + 5
+	if vm.review != nil {
+		if vm.model.width > 0 && vm.model.height > 0 {
+			return lipgloss.Place(vm.model.width, vm.model.height, lipgloss.Center, lipgloss.Center, vm.review.View())
+		}
+		return vm.review.View()
+	}
+
+	sentenceView := vm.renderSentence(vm.model.sentence, vm.model.alignment)
+	contentWidth := lipgloss.Width(string(vm.model.sentence)) + 5
 	sentenceBox := vm.styles.BoxStyle.Width(contentWidth).Render(sentenceView)
 
 	cursor := " "
 	if !vm.model.finished && time.Now().UnixNano()/500000000%2 == 0 {
 		cursor = "_"
 	}
-	inputContent := vm.model.input + cursor
+	inputContent := string(vm.model.input) + cursor
 	inputBox := vm.styles.BoxStyle.Width(contentWidth).Render(inputContent)
 
 	stats := vm.renderStats()
 
-	content := sentenceBox + "\n\n" + inputBox + "\n" + stats
+	content := sentenceBox + "\n\n" + inputBox + "\n"
+	if vm.model.guide && !vm.model.finished {
+		content += "\n" + vm.renderGuide() + "\n"
+	}
+	content += stats
+	if vm.panel != nil {
+		if panel := vm.panel(); panel != "" {
+			content += "\n" + panel
+		}
+	}
 
 	if vm.model.width > 0 && vm.model.height > 0 {
 		centered := lipgloss.Place(
@@ -99,16 +227,32 @@ func (vm *ViewModel) View() string {
 	return content
 }
 
-func (vm *ViewModel) renderSentence(sentence, input string) string {
+// renderSentence colours the text by its alignment with the input, so a
+// skipped or doubled letter only marks itself and not everything after it.
+func (vm *ViewModel) renderSentence(sentence []rune, alignment Alignment) string {
+	ops := make([]Op, len(sentence))
+	for _, edit := range alignment.Edits {
+		if edit.Text < 0 {
+			continue
+		}
+		ops[edit.Text] = edit.Op
+		if edit.Op == OpTransposition {
+			ops[edit.Text+1] = edit.Op
+		}
+	}
+
 	var sentenceView string
 	for i := 0; i < len(sentence); i++ {
-		if i < len(input) {
-			if input[i] == sentence[i] {
+		if i < alignment.End {
+			switch ops[i] {
+			case OpMatch:
 				sentenceView += vm.styles.GreenStyle.Render(string(sentence[i]))
-			} else {
+			case OpOmission:
+				sentenceView += vm.styles.RedStyle.Strikethrough(true).Render(string(sentence[i]))
+			default:
 				sentenceView += vm.styles.RedStyle.Render(string(sentence[i]))
 			}
-		} else if i == len(input) && !vm.model.finished {
+		} else if i == alignment.End && !vm.model.finished {
 			sentenceView += vm.styles.UnderlineStyle.Render(string(sentence[i]))
 		} else {
 			sentenceView += string(sentence[i])
@@ -122,27 +266,66 @@ func (vm *ViewModel) renderStats() string {
 		stats := vm.model.GetStats()
 		return vm.renderFinishedStats(stats)
 	}
-	return "\nPress Enter when done. ESC/CTRL+C to quit"
+	return "\n" + vm.renderDifficulty() + "\nPress Enter when done. ESC/CTRL+C to quit"
+}
+
+// Notices renders the lines registered with AddNotice for the last race.
+func (vm *ViewModel) Notices() []string {
+	var lines []string
+	for _, notice := range vm.notices {
+		if line := notice(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
 }
 
 func (vm *ViewModel) renderFinishedStats(stats Stats) string {
-	statsLines := []string{
+	statsLines := vm.Notices()
+	statsLines = append(statsLines,
 		fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Time:"), vm.styles.ValueStyle.Render(fmt.Sprintf("%.2f seconds", stats.Duration.Seconds()))),
 		fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("WPM:"), vm.styles.ValueStyle.Render(fmt.Sprintf("%.2f", stats.WPM))),
 		fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Accuracy:"), vm.styles.ValueStyle.Render(fmt.Sprintf("%.2f%%", stats.Accuracy))),
+		vm.renderDifficulty(),
+	)
+
+	if stats.Errors.Total() > 0 {
+		statsLines = append(statsLines, fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Uncorrected:"), vm.styles.ValueStyle.Render(fmt.Sprintf(
+			"%d wrong, %d skipped, %d extra, %d swapped",
+			stats.Errors.Substitutions, stats.Errors.Omissions, stats.Errors.Insertions, stats.Errors.Transpositions))))
 	}
 
-	if len(stats.Mistyped) > 0 {
-		mistypedStr := ""
-		for _, mistyped := range stats.Mistyped {
-			mistypedStr += fmt.Sprintf("- %s %s\n", 
-				vm.styles.MistypedKeyStyle.Render(fmt.Sprintf("%q", mistyped.Char)), 
-				vm.styles.ValueStyle.Render(fmt.Sprintf("%d", mistyped.Count)))
-		}
-		statsLines = append(statsLines, vm.styles.LabelStyle.Render("Mistypes: "))
-		statsLines = append(statsLines, mistypedStr)
+	if mistypes := vm.renderMistypes(stats); mistypes != "" {
+		statsLines = append(statsLines, mistypes)
+	}
+
+	if chart := vm.renderChart(stats); chart != "" {
+		statsLines = append(statsLines, chart)
 	}
+	if words := vm.renderWords(stats); words != "" {
+		statsLines = append(statsLines, words)
+	}
+	if fingers := vm.renderFingers(stats); fingers != "" {
+		statsLines = append(statsLines, fingers)
+	}
+	statsLines = append(statsLines, vm.renderHeatmaps(stats), "")
+
+	hint := "Press Enter to restart. "
+	if len(stats.Mistakes()) > 0 {
+		hint += "E to review mistakes. "
+	}
+	if !vm.noRetry && len(stats.RetryWords()) > 0 {
+		hint += "R to retry weak words. "
+	}
+	statsLines = append(statsLines, vm.styles.LabelStyle.Render(strings.TrimSpace(hint)))
 
-	statsLines = append(statsLines, vm.styles.LabelStyle.Render("Press Enter to restart. ESC/CTRL+C/Q to quit"))
+	hint = ""
+	if vm.history != nil {
+		hint += "H to toggle history. "
+	}
+	if !vm.wideHeatmaps() {
+		hint += "M to switch map. "
+	}
+	statsLines = append(statsLines, vm.styles.LabelStyle.Render(hint+"ESC/CTRL+C/Q to quit"))
 	return vm.styles.StatsBoxStyle.Render(strings.Join(statsLines, "\n"))
-} 
\ No newline at end of file
+}
@@ -0,0 +1,227 @@
+package race
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// shownWords is how many words each list on the results screen shows.
+const shownWords = 3
+
+type span struct {
+	start, end int
+}
+
+func wordSpans(text []rune) []span {
+	var spans []span
+	for i := 0; i < len(text); {
+		if unicode.IsSpace(text[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && !unicode.IsSpace(text[i]) {
+			i++
+		}
+		spans = append(spans, span{start, i})
+	}
+	return spans
+}
+
+// WordStat is how one word of the text was typed. Start is the key press
+// before the word, so the first word's time includes getting ready. Errors
+// counts wrong key presses in the word and the space after it.
+type WordStat struct {
+	Word   string        `json:"word"`
+	Start  time.Duration `json:"start"`
+	End    time.Duration `json:"end"`
+	WPM    float64       `json:"wpm"`
+	Errors int           `json:"errors"`
+}
+
+func (w WordStat) Duration() time.Duration {
+	return w.End - w.Start
+}
+
+func wordStats(text []rune, keystrokes []Keystroke) []WordStat {
+	spans := wordSpans(text)
+	var stats []WordStat
+	for _, sp := range spans {
+		stat := WordStat{Word: string(text[sp.start:sp.end])}
+		errorsEnd := sp.end
+		if errorsEnd < len(text) {
+			errorsEnd++
+		}
+
+		found := false
+		for i, key := range keystrokes {
+			if key.Index < sp.start || key.Index >= errorsEnd {
+				continue
+			}
+			if !key.Backspace && !key.Correct() {
+				stat.Errors++
+			}
+			if key.Index >= sp.end {
+				continue
+			}
+			if !found {
+				found = true
+				if i > 0 {
+					stat.Start = keystrokes[i-1].At
+				}
+			}
+			stat.End = key.At
+		}
+		if !found {
+			continue
+		}
+		if minutes := stat.Duration().Minutes(); minutes > 0 {
+			stat.WPM = float64(sp.end-sp.start) / 5 / minutes
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// SlowestWords returns up to n words with the lowest WPM, leaving out the
+// first word and single letters.
+func (s Stats) SlowestWords(n int) []WordStat {
+	var candidates []WordStat
+	for i, word := range s.Words {
+		if i > 0 && len([]rune(word.Word)) > 1 && word.WPM > 0 {
+			candidates = append(candidates, word)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].WPM < candidates[j].WPM })
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// ErrorWords returns up to n words with the most errors.
+func (s Stats) ErrorWords(n int) []WordStat {
+	var candidates []WordStat
+	for _, word := range s.Words {
+		if word.Errors > 0 {
+			candidates = append(candidates, word)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Errors > candidates[j].Errors })
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// WordTotal adds up every time a word was typed.
+type WordTotal struct {
+	Count    int           `json:"count"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration"`
+	Runes    int           `json:"runes"`
+}
+
+func (w WordTotal) WPM() float64 {
+	if w.Duration <= 0 {
+		return 0
+	}
+	return float64(w.Runes) / 5 / w.Duration.Minutes()
+}
+
+func (w WordTotal) ErrorRate() float64 {
+	if w.Count == 0 {
+		return 0
+	}
+	return float64(w.Errors) / float64(w.Count)
+}
+
+// AddWords counts the words of a race into totals. Single letters are
+// left out, as is the first word, whose time includes getting ready.
+func AddWords(totals map[string]WordTotal, stats Stats) {
+	for i, word := range stats.Words {
+		runes := len([]rune(word.Word))
+		if runes < 2 {
+			continue
+		}
+		total := totals[word.Word]
+		total.Count++
+		total.Errors += word.Errors
+		if i > 0 {
+			total.Duration += word.Duration()
+			total.Runes += runes
+		}
+		totals[word.Word] = total
+	}
+}
+
+// ProblemWords ranks words by how often they go wrong and by how far below
+// the overall typing speed they are, returning up to n words that have at
+// least one of the two problems.
+func ProblemWords(totals map[string]WordTotal, n int) []string {
+	var all WordTotal
+	for _, total := range totals {
+		all.Duration += total.Duration
+		all.Runes += total.Runes
+	}
+	average := all.WPM()
+
+	type scored struct {
+		word  string
+		score float64
+	}
+	var problems []scored
+	for word, total := range totals {
+		score := total.ErrorRate()
+		if wpm := total.WPM(); average > 0 && wpm > 0 && wpm < 0.75*average {
+			score += 1 - wpm/average
+		}
+		if score > 0 {
+			problems = append(problems, scored{word, score})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].score != problems[j].score {
+			return problems[i].score > problems[j].score
+		}
+		return problems[i].word < problems[j].word
+	})
+
+	var words []string
+	for i := 0; i < len(problems) && i < n; i++ {
+		words = append(words, problems[i].word)
+	}
+	return words
+}
+
+// renderWords lists the slowest and most mistyped words of this race or,
+// with the history shown, the problem words of every saved race.
+func (vm *ViewModel) renderWords(stats Stats) string {
+	if vm.showHistory && vm.words != nil {
+		problems := ProblemWords(vm.words(), shownWords*2)
+		if len(problems) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Problem words:"), vm.styles.MistypedKeyStyle.Render(strings.Join(problems, " ")))
+	}
+
+	var lines []string
+	if slowest := stats.SlowestWords(shownWords); len(slowest) > 0 {
+		parts := make([]string, len(slowest))
+		for i, word := range slowest {
+			parts[i] = fmt.Sprintf("%s %s", word.Word, vm.styles.ValueStyle.Render(fmt.Sprintf("%.0f", word.WPM)))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Slowest words (WPM):"), strings.Join(parts, "  ")))
+	}
+	if mistyped := stats.ErrorWords(shownWords); len(mistyped) > 0 {
+		parts := make([]string, len(mistyped))
+		for i, word := range mistyped {
+			parts[i] = fmt.Sprintf("%s %s", vm.styles.MistypedKeyStyle.Render(word.Word), vm.styles.ValueStyle.Render(fmt.Sprintf("%d", word.Errors)))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", vm.styles.LabelStyle.Render("Most errors:"), strings.Join(parts, "  ")))
+	}
+	return strings.Join(lines, "\n")
+}
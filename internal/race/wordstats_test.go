@@ -0,0 +1,72 @@
+package race
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProblemWords(t *testing.T) {
+	// Five runes a second is 60 WPM.
+	fast := WordTotal{Count: 4, Duration: time.Second, Runes: 5}
+	tests := []struct {
+		name   string
+		totals map[string]WordTotal
+		n      int
+		want   []string
+	}{
+		{"none", nil, 5, nil},
+		{"no problems", map[string]WordTotal{"the": fast, "and": fast}, 5, nil},
+		{
+			name: "errors and slow words",
+			totals: map[string]WordTotal{
+				"the":    fast,
+				"and":    fast,
+				"rhythm": {Count: 2, Errors: 2, Duration: time.Second, Runes: 5},
+				"typo":   {Count: 4, Errors: 1, Duration: time.Second, Runes: 5},
+				"slow":   {Count: 4, Duration: 4 * time.Second, Runes: 5},
+			},
+			n:    5,
+			want: []string{"rhythm", "slow", "typo"},
+		},
+		{
+			name: "at most n",
+			totals: map[string]WordTotal{
+				"b": {Count: 2, Errors: 1},
+				"a": {Count: 2, Errors: 1},
+				"c": {Count: 1, Errors: 1},
+			},
+			n:    2,
+			want: []string{"c", "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProblemWords(tt.totals, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProblemWords = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderProblemWords(t *testing.T) {
+	vm := NewViewModel(NewModel(Options{}))
+	vm.SetHistory(func() []Stats {
+		t.Fatal("problem words read the whole history")
+		return nil
+	})
+	vm.SetWordTotals(func() map[string]WordTotal {
+		return map[string]WordTotal{"rhythm": {Count: 2, Errors: 2}, "the": {Count: 5}}
+	})
+
+	stats := typing("ab cd", "ab cx")
+	stats.Words = []WordStat{{Word: "ab", WPM: 50}, {Word: "cd", WPM: 40, Errors: 1}}
+	if got := vm.renderWords(stats); !strings.Contains(got, "Most errors:") || strings.Contains(got, "rhythm") {
+		t.Errorf("words of this race = %q", got)
+	}
+	vm.showHistory = true
+	if got := vm.renderWords(stats); !strings.Contains(got, "Problem words:") || !strings.Contains(got, "rhythm") {
+		t.Errorf("problem words = %q, want rhythm listed", got)
+	}
+}
@@ -0,0 +1,40 @@
+package rating
+
+import (
+	"fmt"
+	"time"
+
+	"go-typ0/internal/race"
+	"go-typ0/internal/ui"
+)
+
+// Attach updates the rating as vm finishes races and shows it on the
+// results screen. It is a race.RecorderFunc.
+func Attach(vm *race.ViewModel, mode string, opts race.Options) func() error {
+	r, err := Load()
+	styles := ui.NewStyles()
+
+	var notice string
+	vm.OnFinish(func(stats race.Stats) {
+		notice = ""
+		if r == nil {
+			return
+		}
+
+		change, ok := r.Update(stats, time.Now())
+		if !ok {
+			return
+		}
+		text := fmt.Sprintf("Rating: %.0f ±%.0f (%+.0f)", r.Rating, r.Deviation, change)
+		if r.Provisional() {
+			text += " provisional"
+		}
+		notice = styles.LabelStyle.Render(text)
+
+		if saveErr := r.Save(); saveErr != nil {
+			err = saveErr
+		}
+	})
+	vm.AddNotice(func() string { return notice })
+	return func() error { return err }
+}
@@ -0,0 +1,82 @@
+package rating
+
+import (
+	"fmt"
+
+	"go-typ0/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	var rebuild bool
+
+	cmd := &cobra.Command{
+		Use:   "rating",
+		Short: "Show your skill rating and how it has moved",
+		Long: `Show your skill rating: one number for how well you type that stays
+comparable between short lowercase races and long punctuated quotes.
+
+Every complete race is rated from its WPM, adjusted for accuracy and for
+the text's difficulty score (see "typ0 race --difficulty"): speed counts
+for 1.4 times as much on text scoring 4, like random words. Your rating
+moves towards each race's rating, more while it is still uncertain. The
+number after ± is that uncertainty; it shrinks as you race and grows again
+after time away. A rating of 1000 is 40 WPM on the easiest text without
+mistakes, and each doubling of speed adds 400.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := history.Load()
+			if err != nil {
+				return err
+			}
+
+			type month struct {
+				name   string
+				rating Rating
+				races  int
+			}
+			var months []month
+			replayed := Replay(records, func(record history.Record, r Rating) {
+				name := record.Time.Local().Format("2006-01")
+				if len(months) == 0 || months[len(months)-1].name != name {
+					months = append(months, month{name: name})
+				}
+				months[len(months)-1].rating = r
+				months[len(months)-1].races++
+			})
+
+			r := replayed
+			if rebuild {
+				if err := r.Save(); err != nil {
+					return err
+				}
+			} else if r, err = Load(); err != nil {
+				return err
+			}
+
+			if r.Races == 0 {
+				fmt.Println("No rated races yet. Start typing: typ0 race")
+				return nil
+			}
+
+			status := ""
+			if r.Provisional() {
+				status = " (provisional)"
+			}
+			fmt.Printf("Rating: %.0f ±%.0f over %d races%s\n", r.Rating, r.Deviation, r.Races, status)
+			fmt.Printf("Last rated: %s\n", r.Updated.Local().Format("2006-01-02"))
+
+			if len(months) > 0 {
+				fmt.Printf("\n%-8s %7s %5s %6s\n", "MONTH", "RATING", "±", "RACES")
+				for _, m := range months {
+					fmt.Printf("%-8s %7.0f %5.0f %6d\n", m.name, m.rating.Rating, m.rating.Deviation, m.races)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "Work the rating out again from the whole history")
+	return cmd
+}
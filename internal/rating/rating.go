@@ -0,0 +1,139 @@
+package rating
+
+import (
+	"math"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+	"go-typ0/internal/storage"
+)
+
+const ratingFile = "rating.json"
+
+// formula is bumped whenever races are rated differently, so saved ratings
+// are worked out again.
+const formula = 1
+
+// The rating works like Glicko: a skill estimate with a deviation saying
+// how sure it is. Each race is rated on the same scale from its speed,
+// accuracy and text difficulty, and pulls the rating towards it more the
+// less certain the rating is. The deviation shrinks with every race and
+// grows again with time away.
+const (
+	initialRating    = 1000
+	initialDeviation = 350
+	minDeviation     = 30
+	// raceDeviation is how far a single race typically strays from the
+	// typist's skill.
+	raceDeviation = 150
+	// drift is how much the deviation grows per day without racing.
+	drift = 15
+	// provisional is the deviation above which the rating is still settling.
+	provisional = 100
+
+	// A race at baseWPM on the easiest text with no mistakes rates
+	// baseRating; every doubling of that speed adds doubling points.
+	baseWPM    = 40
+	baseRating = 1000
+	doubling   = 400
+	// Speed counts for 1 + score/difficultyScale times as much on text with
+	// that difficulty score.
+	difficultyScale = 10
+)
+
+type Rating struct {
+	Formula   int       `json:"formula"`
+	Rating    float64   `json:"rating"`
+	Deviation float64   `json:"deviation"`
+	Races     int       `json:"races"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Performance rates one race on the rating scale. Only complete races with
+// their text count, and not follow-up retries.
+func Performance(stats race.Stats) (float64, bool) {
+	if stats.Retry || !stats.Complete() || stats.WPM <= 0 {
+		return 0, false
+	}
+	effective := stats.WPM * (1 + stats.Difficulty/difficultyScale) * math.Pow(stats.Accuracy/100, 2)
+	if effective <= 0 {
+		return 0, false
+	}
+	return baseRating + doubling*math.Log2(effective/baseWPM), true
+}
+
+// Update counts a race finished at at towards the rating and returns how
+// much the rating changed, or false if the race does not count.
+func (r *Rating) Update(stats race.Stats, at time.Time) (float64, bool) {
+	performance, ok := Performance(stats)
+	if !ok {
+		return 0, false
+	}
+
+	if r.Races == 0 {
+		r.Rating, r.Deviation = initialRating, initialDeviation
+	} else if days := at.Sub(r.Updated).Hours() / 24; days > 0 {
+		r.Deviation = math.Min(initialDeviation, math.Sqrt(r.Deviation*r.Deviation+drift*drift*days))
+	}
+
+	variance := r.Deviation * r.Deviation
+	weight := variance / (variance + raceDeviation*raceDeviation)
+	previous := r.Rating
+	r.Rating += weight * (performance - r.Rating)
+	r.Deviation = math.Max(minDeviation, math.Sqrt(variance*(1-weight)))
+	r.Races++
+	r.Updated = at.UTC()
+	return r.Rating - previous, true
+}
+
+// Provisional reports whether too few races have been rated for the
+// rating to mean much yet.
+func (r *Rating) Provisional() bool {
+	return r.Races == 0 || r.Deviation > provisional
+}
+
+// Replay rates every race in records from scratch, calling fn, if not nil,
+// after each one that counts.
+func Replay(records []history.Record, fn func(history.Record, Rating)) *Rating {
+	r := &Rating{Formula: formula}
+	for _, record := range records {
+		if _, ok := r.Update(record.Stats, record.Time); ok && fn != nil {
+			fn(record, *r)
+		}
+	}
+	return r
+}
+
+// Load reads the rating, working it out from the history the first time
+// and after the formula changes.
+func Load() (*Rating, error) {
+	var r *Rating
+	if err := storage.Load(ratingFile, &r); err != nil {
+		return nil, err
+	}
+	if r != nil && r.Formula == formula {
+		return r, nil
+	}
+
+	records, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Replay(records, nil), nil
+}
+
+func (r *Rating) Save() error {
+	return storage.Save(ratingFile, r)
+}
+
+// Imported rates the history again from the start, as races added from
+// elsewhere may be older than the ones already rated. It is a history
+// import hook.
+func Imported([]history.Record) error {
+	records, err := history.Load()
+	if err != nil {
+		return err
+	}
+	return Replay(records, nil).Save()
+}
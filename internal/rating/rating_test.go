@@ -0,0 +1,103 @@
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+)
+
+// raced is a complete race at wpm and accuracy on text with the given
+// difficulty score.
+func raced(wpm, accuracy, score float64) race.Stats {
+	return race.Stats{Text: "abc", Input: "abc", WPM: wpm, Accuracy: accuracy, Difficulty: score, Finished: true}
+}
+
+func TestPerformance(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats race.Stats
+		want  float64
+		ok    bool
+	}{
+		{"base", raced(40, 100, 1e-9), 1000, true},
+		{"double speed", raced(80, 100, 1e-9), 1400, true},
+		{"half speed", raced(20, 100, 1e-9), 600, true},
+		{"hard text", raced(40, 100, 10), 1400, true},
+		{"mistakes", raced(80, 50, 1e-9), 600, true},
+		{"retry", func() race.Stats { s := raced(40, 100, 1); s.Retry = true; return s }(), 0, false},
+		{"ended early", race.Stats{Text: "abc", Input: "a", WPM: 40, Accuracy: 100, Finished: true}, 0, false},
+		{"imported without text", race.Stats{WPM: 40, Accuracy: 100, Finished: true}, 0, false},
+		{"no speed", raced(0, 100, 1), 0, false},
+		{"no accuracy", raced(40, 0, 1), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Performance(tt.stats)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: Performance = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var r Rating
+	if !r.Provisional() {
+		t.Error("a rating without races is not provisional")
+	}
+
+	if _, ok := r.Update(race.Stats{}, start); ok || r.Races != 0 {
+		t.Fatal("a race that does not count changed the rating")
+	}
+
+	// The first race moves the rating most of the way from 1000 towards
+	// its performance of 1400.
+	change, ok := r.Update(raced(80, 100, 1e-9), start)
+	weight := 350.0 * 350 / (350*350 + 150*150)
+	if !ok || math.Abs(change-400*weight) > 1e-6 || math.Abs(r.Rating-(1000+400*weight)) > 1e-6 {
+		t.Errorf("first race: change %v, rating %v, want %v", change, r.Rating, 1000+400*weight)
+	}
+	if math.Abs(r.Deviation-math.Sqrt(350*350*(1-weight))) > 1e-6 || !r.Provisional() {
+		t.Errorf("first race: deviation %v, want %v and provisional", r.Deviation, math.Sqrt(350*350*(1-weight)))
+	}
+
+	for i := 1; i < 50; i++ {
+		r.Update(raced(80, 100, 1e-9), start.Add(time.Duration(i)*time.Minute))
+	}
+	if math.Abs(r.Rating-1400) > 2 || r.Deviation != minDeviation || r.Provisional() || r.Races != 50 {
+		t.Errorf("after 50 races: %+v, want about 1400 ±%d and settled", r, minDeviation)
+	}
+
+	// A long break makes the rating uncertain again, so the next race
+	// counts for more.
+	settled := r
+	settledChange, _ := settled.Update(raced(40, 100, 1e-9), r.Updated.Add(time.Hour))
+	breakChange, _ := r.Update(raced(40, 100, 1e-9), r.Updated.AddDate(0, 6, 0))
+	if breakChange >= settledChange {
+		t.Errorf("after a break the rating moved %v, after an hour %v; want a larger drop after the break", breakChange, settledChange)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Time: start, Stats: raced(60, 98, 4)},
+		{Time: start.Add(time.Hour), Stats: race.Stats{WPM: 90, Accuracy: 100}},
+		{Time: start.Add(2 * time.Hour), Stats: raced(65, 97, 4)},
+	}
+
+	var seen int
+	r := Replay(records, func(history.Record, Rating) { seen++ })
+	if seen != 2 || r.Races != 2 || r.Formula != formula {
+		t.Errorf("Replay rated %d races (%d calls), formula %d; want 2 and formula %d", r.Races, seen, r.Formula, formula)
+	}
+
+	var direct Rating
+	direct.Update(records[0].Stats, records[0].Time)
+	direct.Update(records[2].Stats, records[2].Time)
+	if math.Abs(direct.Rating-r.Rating) > 1e-9 || math.Abs(direct.Deviation-r.Deviation) > 1e-9 {
+		t.Errorf("Replay = %+v, updating one by one gives %+v", r, direct)
+	}
+}
@@ -0,0 +1,194 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"go-typ0/internal/history"
+)
+
+// Period sums up the races of one day or week.
+type Period struct {
+	Start    time.Time
+	Races    int
+	WPM      float64
+	Accuracy float64
+	Duration time.Duration
+}
+
+// Periods groups the comparable races into days, or weeks starting on
+// Monday, in local time, oldest first. Periods without races are left out.
+func Periods(records []history.Record, weekly bool) []Period {
+	byStart := make(map[time.Time]*Period)
+	var starts []time.Time
+	for _, record := range records {
+		if !record.Stats.Comparable() {
+			continue
+		}
+		start := day(record.Time)
+		if weekly {
+			start = week(record.Time)
+		}
+
+		p, ok := byStart[start]
+		if !ok {
+			p = &Period{Start: start}
+			byStart[start] = p
+			starts = append(starts, start)
+		}
+		p.Races++
+		p.WPM += record.Stats.WPM
+		p.Accuracy += record.Stats.Accuracy
+		p.Duration += record.Stats.Duration
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	periods := make([]Period, len(starts))
+	for i, start := range starts {
+		p := *byStart[start]
+		p.WPM /= float64(p.Races)
+		p.Accuracy /= float64(p.Races)
+		periods[i] = p
+	}
+	return periods
+}
+
+func day(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func week(t time.Time) time.Time {
+	d := day(t)
+	offset := (int(d.Weekday()) + 6) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+// MovingAverage averages each value with up to n-1 values before it.
+func MovingAverage(values []float64, n int) []float64 {
+	averages := make([]float64, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= n {
+			sum -= values[i-n]
+		}
+		averages[i] = sum / float64(min(i+1, n))
+	}
+	return averages
+}
+
+// ModeStat sums up the races of one mode.
+type ModeStat struct {
+	Mode     string
+	Races    int
+	WPM      float64
+	Best     float64
+	Accuracy float64
+	Duration time.Duration
+}
+
+// Modes breaks records down by mode, most played first.
+func Modes(records []history.Record) []ModeStat {
+	byMode := make(map[string]*ModeStat)
+	for _, record := range records {
+		m, ok := byMode[record.Mode]
+		if !ok {
+			m = &ModeStat{Mode: record.Mode}
+			byMode[record.Mode] = m
+		}
+		m.Races++
+		m.WPM += record.Stats.WPM
+		m.Accuracy += record.Stats.Accuracy
+		m.Best = max(m.Best, record.Stats.WPM)
+		m.Duration += record.Stats.Duration
+	}
+
+	modes := make([]ModeStat, 0, len(byMode))
+	for _, m := range byMode {
+		m.WPM /= float64(m.Races)
+		m.Accuracy /= float64(m.Races)
+		modes = append(modes, *m)
+	}
+	sort.Slice(modes, func(i, j int) bool {
+		if modes[i].Races != modes[j].Races {
+			return modes[i].Races > modes[j].Races
+		}
+		return modes[i].Mode < modes[j].Mode
+	})
+	return modes
+}
+
+// Summary covers every record. The speeds and accuracy come from the
+// Comparable races only.
+type Summary struct {
+	Races      int
+	Duration   time.Duration
+	Comparable int
+	WPM        float64
+	Best       float64
+	Accuracy   float64
+	// Recent and Before average the last recentRaces races and the ones
+	// before them, to show the direction things are going.
+	Recent float64
+	Before float64
+}
+
+const recentRaces = 10
+
+func Summarize(records []history.Record) Summary {
+	var (
+		s          Summary
+		comparable []history.Record
+	)
+	for _, record := range records {
+		s.Races++
+		s.Duration += record.Stats.Duration
+		if record.Stats.Comparable() {
+			comparable = append(comparable, record)
+		}
+	}
+
+	for i, record := range comparable {
+		s.Comparable++
+		s.WPM += record.Stats.WPM
+		s.Accuracy += record.Stats.Accuracy
+		s.Best = max(s.Best, record.Stats.WPM)
+		if i >= len(comparable)-recentRaces {
+			s.Recent += record.Stats.WPM
+		} else if i >= len(comparable)-2*recentRaces {
+			s.Before += record.Stats.WPM
+		}
+	}
+	if s.Comparable == 0 {
+		return s
+	}
+
+	s.WPM /= float64(s.Comparable)
+	s.Accuracy /= float64(s.Comparable)
+	recent := min(s.Comparable, recentRaces)
+	s.Recent /= float64(recent)
+	if before := min(s.Comparable-recent, recentRaces); before > 0 {
+		s.Before /= float64(before)
+	}
+	return s
+}
+
+// Day is the practice of one day.
+type Day struct {
+	Races    int
+	Duration time.Duration
+}
+
+// Days counts the races and time practised on each day, keyed by local
+// midnight. Every race counts, including imported ones without a duration.
+func Days(records []history.Record) map[time.Time]Day {
+	days := make(map[time.Time]Day)
+	for _, record := range records {
+		d := days[day(record.Time)]
+		d.Races++
+		d.Duration += record.Stats.Duration
+		days[day(record.Time)] = d
+	}
+	return days
+}
@@ -0,0 +1,164 @@
+package stats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/race"
+)
+
+// at is a local time on day d of January 2024, which starts on a Monday.
+func at(d, hour int) time.Time {
+	return time.Date(2024, 1, d, hour, 0, 0, 0, time.Local)
+}
+
+// typed is a race typed to the end.
+func typed(when time.Time, wpm, accuracy float64, seconds int) history.Record {
+	return history.Record{Time: when, Stats: race.Stats{
+		Text: "ab", Input: "ab", WPM: wpm, Accuracy: accuracy,
+		Duration: time.Duration(seconds) * time.Second, Finished: true,
+	}}
+}
+
+// endedEarly is a race left before the end of its text.
+func endedEarly(when time.Time, wpm float64) history.Record {
+	return history.Record{Time: when, Stats: race.Stats{Text: "ab cd", Input: "ab", WPM: wpm, Accuracy: 100, Duration: 5 * time.Second, Finished: true}}
+}
+
+// imported is a race imported without its text or duration.
+func imported(when time.Time, wpm float64, finished bool) history.Record {
+	return history.Record{Time: when, Stats: race.Stats{WPM: wpm, Accuracy: 100, Finished: finished}}
+}
+
+func TestPeriods(t *testing.T) {
+	retry := typed(at(2, 9), 200, 100, 10)
+	retry.Stats.Retry = true
+	records := []history.Record{
+		typed(at(1, 9), 40, 90, 10),
+		typed(at(1, 20), 60, 100, 20),
+		endedEarly(at(1, 21), 200),
+		retry,
+		imported(at(3, 9), 50, true),
+		imported(at(3, 10), 200, false),
+		typed(at(8, 9), 70, 96, 30),
+	}
+
+	tests := []struct {
+		name   string
+		weekly bool
+		want   []Period
+	}{
+		{"daily", false, []Period{
+			{Start: at(1, 0), Races: 2, WPM: 50, Accuracy: 95, Duration: 30 * time.Second},
+			{Start: at(3, 0), Races: 1, WPM: 50, Accuracy: 100},
+			{Start: at(8, 0), Races: 1, WPM: 70, Accuracy: 96, Duration: 30 * time.Second},
+		}},
+		{"weekly", true, []Period{
+			{Start: at(1, 0), Races: 3, WPM: 50, Accuracy: (90 + 100 + 100) / 3.0, Duration: 30 * time.Second},
+			{Start: at(8, 0), Races: 1, WPM: 70, Accuracy: 96, Duration: 30 * time.Second},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Periods(records, tt.weekly); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Periods =\n%+v, want\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := Periods([]history.Record{endedEarly(at(1, 9), 50)}, false); len(got) != 0 {
+		t.Errorf("Periods of races ended early = %+v, want none", got)
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		values []float64
+		n      int
+		want   []float64
+	}{
+		{nil, 3, []float64{}},
+		{[]float64{1, 2, 3, 4, 5}, 1, []float64{1, 2, 3, 4, 5}},
+		{[]float64{1, 2, 3, 4, 5}, 3, []float64{1, 1.5, 2, 3, 4}},
+		{[]float64{2, 4}, 7, []float64{2, 3}},
+	}
+	for _, tt := range tests {
+		if got := MovingAverage(tt.values, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MovingAverage(%v, %d) = %v, want %v", tt.values, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// series is n races typed to the end at the given speed.
+	series := func(n int, wpm float64) []history.Record {
+		records := make([]history.Record, n)
+		for i := range records {
+			records[i] = typed(at(1, 9), wpm, 100, 10)
+		}
+		return records
+	}
+
+	tests := []struct {
+		name    string
+		records []history.Record
+		want    Summary
+	}{
+		{"none", nil, Summary{}},
+		{
+			name:    "few",
+			records: []history.Record{typed(at(1, 9), 40, 90, 10), typed(at(2, 9), 60, 100, 20)},
+			want:    Summary{Races: 2, Comparable: 2, Duration: 30 * time.Second, WPM: 50, Best: 60, Accuracy: 95, Recent: 50},
+		},
+		{
+			name:    "trend",
+			records: append(append(series(5, 30), series(10, 40)...), series(10, 50)...),
+			want:    Summary{Races: 25, Comparable: 25, Duration: 250 * time.Second, WPM: 42, Best: 50, Accuracy: 100, Recent: 50, Before: 40},
+		},
+		{
+			name: "races that do not count",
+			records: []history.Record{
+				typed(at(1, 9), 40, 90, 10),
+				endedEarly(at(1, 10), 200),
+				imported(at(1, 11), 60, true),
+				imported(at(1, 12), 200, false),
+			},
+			want: Summary{Races: 4, Comparable: 2, Duration: 15 * time.Second, WPM: 50, Best: 60, Accuracy: 95, Recent: 50},
+		},
+		{
+			name:    "none count",
+			records: []history.Record{endedEarly(at(1, 10), 200)},
+			want:    Summary{Races: 1, Duration: 5 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.records)
+			if math.Abs(got.WPM-tt.want.WPM) < 1e-9 {
+				got.WPM = tt.want.WPM
+			}
+			if got != tt.want {
+				t.Errorf("Summarize =\n%+v, want\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDays(t *testing.T) {
+	records := []history.Record{
+		typed(at(1, 9), 40, 90, 10),
+		endedEarly(at(1, 23), 50),
+		imported(at(2, 9), 50, true),
+		imported(at(2, 10), 50, false),
+	}
+	want := map[time.Time]Day{
+		at(1, 0): {Races: 2, Duration: 15 * time.Second},
+		at(2, 0): {Races: 2},
+	}
+	if got := Days(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("Days = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+
+	"go-typ0/internal/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show your progress over time",
+		Long: `Open a dashboard of every saved race: WPM and accuracy trends by day or
+week with moving averages, a breakdown by mode and a calendar of the days
+you practised.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := history.Load()
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Println("No races saved yet. Start typing: typ0 race")
+				return nil
+			}
+
+			p := tea.NewProgram(NewDashboard(records))
+			if _, err := p.Run(); err != nil {
+				fmt.Println("Error running program: ", err)
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
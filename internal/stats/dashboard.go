@@ -0,0 +1,315 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go-typ0/internal/history"
+	"go-typ0/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type tab int
+
+const (
+	tabTrends tab = iota
+	tabModes
+	tabCalendar
+)
+
+var tabNames = []string{"Trends", "Modes", "Calendar"}
+
+const (
+	movingWindow = 7
+	tableRows    = 8
+)
+
+var (
+	sparks = []rune("▁▂▃▄▅▆▇█")
+	// calendarLevels go from no practice to the most practised day; the
+	// glyphs alone tell them apart when colours are off.
+	calendarGlyphs = []string{"·", "░", "▒", "▓", "█"}
+	calendarColors = []lipgloss.Color{"8", "22", "28", "34", "40"}
+)
+
+// Dashboard is the full-screen view of the saved history.
+type Dashboard struct {
+	records []history.Record
+	tab     tab
+	weekly  bool
+	now     time.Time
+
+	width  int
+	height int
+	styles *ui.Styles
+}
+
+func NewDashboard(records []history.Record) *Dashboard {
+	return &Dashboard{
+		records: records,
+		now:     time.Now(),
+		styles:  ui.NewStyles(),
+	}
+}
+
+func (d *Dashboard) Init() tea.Cmd {
+	return tea.EnterAltScreen
+}
+
+func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return d, tea.Quit
+		case "tab", "right", "l":
+			d.tab = (d.tab + 1) % tab(len(tabNames))
+		case "shift+tab", "left", "h":
+			d.tab = (d.tab + tab(len(tabNames)) - 1) % tab(len(tabNames))
+		case "1", "2", "3":
+			d.tab = tab(msg.String()[0] - '1')
+		case "w":
+			d.weekly = !d.weekly
+		}
+	}
+	return d, nil
+}
+
+func (d *Dashboard) View() string {
+	var body string
+	switch d.tab {
+	case tabTrends:
+		body = d.renderTrends()
+	case tabModes:
+		body = d.renderModes()
+	case tabCalendar:
+		body = d.renderCalendar()
+	}
+
+	content := strings.Join([]string{
+		d.renderTabs(),
+		d.styles.StatsBoxStyle.Render(d.renderSummary() + "\n\n" + body),
+		d.styles.LabelStyle.Render("Tab/←/→ to switch view. W for days or weeks. ESC/Q to quit"),
+	}, "\n")
+
+	if d.width > 0 && d.height > 0 {
+		return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, content)
+	}
+	return content
+}
+
+func (d *Dashboard) renderTabs() string {
+	tabs := make([]string, len(tabNames))
+	for i, name := range tabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if tab(i) == d.tab {
+			tabs[i] = d.styles.ValueStyle.Reverse(true).Render(label)
+		} else {
+			tabs[i] = d.styles.LabelStyle.Render(label)
+		}
+	}
+	return strings.Join(tabs, " ")
+}
+
+func (d *Dashboard) renderSummary() string {
+	s := Summarize(d.records)
+	trend := ""
+	if s.Before > 0 {
+		delta := s.Recent - s.Before
+		style := d.styles.GreenStyle
+		if delta < 0 {
+			style = d.styles.RedStyle
+		}
+		trend = "  " + style.Render(fmt.Sprintf("%+.1f", delta)) + d.styles.LabelStyle.Render(fmt.Sprintf(" vs the %d before", recentRaces))
+	}
+
+	return strings.Join([]string{
+		fmt.Sprintf("%s %s  %s %s  %s %s",
+			d.styles.LabelStyle.Render("Races:"), d.styles.ValueStyle.Render(fmt.Sprintf("%d", s.Races)),
+			d.styles.LabelStyle.Render("Time:"), d.styles.ValueStyle.Render(formatDuration(s.Duration)),
+			d.styles.LabelStyle.Render("Best:"), d.styles.ValueStyle.Render(fmt.Sprintf("%.1f WPM", s.Best))),
+		fmt.Sprintf("%s %s  %s %s",
+			d.styles.LabelStyle.Render("Average:"), d.styles.ValueStyle.Render(fmt.Sprintf("%.1f WPM", s.WPM)),
+			d.styles.LabelStyle.Render("Accuracy:"), d.styles.ValueStyle.Render(fmt.Sprintf("%.1f%%", s.Accuracy))),
+		fmt.Sprintf("%s %s%s",
+			d.styles.LabelStyle.Render(fmt.Sprintf("Last %d races:", min(s.Comparable, recentRaces))),
+			d.styles.ValueStyle.Render(fmt.Sprintf("%.1f WPM", s.Recent)), trend),
+	}, "\n")
+}
+
+// renderTrends plots the recent periods as sparklines and lists the last
+// few in a table.
+func (d *Dashboard) renderTrends() string {
+	periods := Periods(d.records, d.weekly)
+	if len(periods) == 0 {
+		return d.styles.LabelStyle.Render("No races typed to the end yet.")
+	}
+	unit, heading, format := "day", "Day", "Mon Jan 2"
+	if d.weekly {
+		unit, heading, format = "week", "Week of", "Jan 2 2006"
+	}
+
+	columns := 60
+	if d.width > 0 {
+		columns = max(10, min(columns, d.width-30))
+	}
+	shown := periods[max(0, len(periods)-columns):]
+
+	wpm := make([]float64, len(periods))
+	accuracy := make([]float64, len(periods))
+	for i, p := range periods {
+		wpm[i], accuracy[i] = p.WPM, p.Accuracy
+	}
+	moving := MovingAverage(wpm, movingWindow)
+	from := len(periods) - len(shown)
+
+	// WPM and its moving average share a scale so they can be compared.
+	low, high := bounds(wpm[from:], moving[from:])
+	accLow, accHigh := bounds(accuracy[from:])
+
+	label := func(s string) string { return d.styles.LabelStyle.Render(fmt.Sprintf("%-12s", s)) }
+	lines := []string{
+		d.styles.LabelStyle.Render(fmt.Sprintf("By %s (%d %ss with races)", unit, len(periods), unit)),
+		"",
+		label("WPM") + d.styles.ValueStyle.Render(sparkline(wpm[from:], low, high)),
+		label(fmt.Sprintf("Avg of %d", movingWindow)) + d.styles.GreenStyle.Render(sparkline(moving[from:], low, high)),
+		label("Accuracy") + d.styles.ValueStyle.Render(sparkline(accuracy[from:], accLow, accHigh)),
+		label("") + d.styles.DimStyle.Render(fmt.Sprintf("%s … %s, WPM %.0f–%.0f", shown[0].Start.Format(format), shown[len(shown)-1].Start.Format(format), low, high)),
+		"",
+		d.styles.LabelStyle.Render(fmt.Sprintf("%-12s %6s %8s %8s %9s %8s", heading, "Races", "WPM", "Avg", "Accuracy", "Time")),
+	}
+	for i := max(0, len(periods)-tableRows); i < len(periods); i++ {
+		p := periods[i]
+		lines = append(lines, fmt.Sprintf("%-12s %6d %8.1f %8.1f %8.1f%% %8s",
+			p.Start.Format(format), p.Races, p.WPM, moving[i], p.Accuracy, formatDuration(p.Duration)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *Dashboard) renderModes() string {
+	lines := []string{d.styles.LabelStyle.Render(fmt.Sprintf("%-10s %6s %8s %8s %9s %8s", "Mode", "Races", "WPM", "Best", "Accuracy", "Time"))}
+	for _, m := range Modes(d.records) {
+		lines = append(lines, fmt.Sprintf("%-10s %6d %8.1f %8.1f %8.1f%% %8s",
+			m.Mode, m.Races, m.WPM, m.Best, m.Accuracy, formatDuration(m.Duration)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCalendar draws practice time per day GitHub style: a column per
+// week, Monday at the top, as many weeks as fit.
+func (d *Dashboard) renderCalendar() string {
+	weeks := 52
+	if d.width > 0 {
+		weeks = max(4, min(weeks, (d.width-16)/2))
+	}
+
+	days := Days(d.records)
+	most := time.Duration(0)
+	for _, day := range days {
+		most = max(most, day.Duration)
+	}
+	// Days with races but no recorded time, as imported from TypeRacer,
+	// show at the lowest level.
+	level := func(day Day) int {
+		if day.Races == 0 {
+			return 0
+		}
+		if most <= 0 {
+			return 1
+		}
+		return 1 + int(math.Min(3, float64(day.Duration)/float64(most)*4))
+	}
+
+	first := week(d.now).AddDate(0, 0, -7*(weeks-1))
+
+	// Month names go above the first week starting in that month, where
+	// there is room.
+	months := []rune(strings.Repeat(" ", 4+2*weeks))
+	free := 0
+	for w := 0; w < weeks; w++ {
+		start := first.AddDate(0, 0, 7*w)
+		col := 4 + 2*w
+		leading := w == 0 && start.AddDate(0, 0, 14).Month() == start.Month()
+		if (leading || start.Day() <= 7) && col >= free {
+			copy(months[col:], []rune(start.Format("Jan")))
+			free = col + 4
+		}
+	}
+
+	lines := []string{d.styles.LabelStyle.Render(strings.TrimRight(string(months), " "))}
+	practised := 0
+	for weekday := 0; weekday < 7; weekday++ {
+		var row strings.Builder
+		switch weekday {
+		case 0:
+			row.WriteString(d.styles.LabelStyle.Render("Mon "))
+		case 2:
+			row.WriteString(d.styles.LabelStyle.Render("Wed "))
+		case 4:
+			row.WriteString(d.styles.LabelStyle.Render("Fri "))
+		default:
+			row.WriteString("    ")
+		}
+		for w := 0; w < weeks; w++ {
+			date := first.AddDate(0, 0, 7*w+weekday)
+			if date.After(d.now) {
+				row.WriteString("  ")
+				continue
+			}
+			l := level(days[date])
+			if l > 0 {
+				practised++
+			}
+			row.WriteString(lipgloss.NewStyle().Foreground(calendarColors[l]).Render(calendarGlyphs[l]) + " ")
+		}
+		lines = append(lines, row.String())
+	}
+
+	legend := make([]string, len(calendarGlyphs))
+	for i, glyph := range calendarGlyphs {
+		legend[i] = lipgloss.NewStyle().Foreground(calendarColors[i]).Render(glyph)
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("%s %s  %s", d.styles.LabelStyle.Render("Days practised:"), d.styles.ValueStyle.Render(fmt.Sprintf("%d", practised)),
+			d.styles.LabelStyle.Render("less ")+strings.Join(legend, " ")+d.styles.LabelStyle.Render(" more")))
+	return strings.Join(lines, "\n")
+}
+
+func bounds(series ...[]float64) (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, values := range series {
+		for _, v := range values {
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	if math.IsInf(low, 0) {
+		return 0, 0
+	}
+	return low, high
+}
+
+func sparkline(values []float64, low, high float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		i := len(sparks) / 2
+		if high > low {
+			i = int(math.Round((v - low) / (high - low) * float64(len(sparks)-1)))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultProfile = "default"
+	profilesDir    = "profiles"
+	activeFile     = "profile"
+)
+
+var (
+	profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// override is set from --profile and wins over $TYP0_PROFILE and the
+	// profile chosen with "typ0 profile switch".
+	override string
+)
+
+// UseProfile makes name the active profile for this run. It must exist.
+func UseProfile(name string) error {
+	if err := checkExists(name); err != nil {
+		return err
+	}
+	override = name
+	return nil
+}
+
+// ActiveProfile returns the profile in use: --profile, then $TYP0_PROFILE,
+// then the one last switched to.
+func ActiveProfile() (string, error) {
+	if name := overridden(); name != "" {
+		return name, checkExists(name)
+	}
+	name, err := switched()
+	if err != nil {
+		return "", err
+	}
+	return name, checkExists(name)
+}
+
+// overridden returns the profile chosen for this run only, by --profile or
+// $TYP0_PROFILE, or "" for none.
+func overridden() string {
+	if override != "" {
+		return override
+	}
+	return os.Getenv("TYP0_PROFILE")
+}
+
+// switched returns the profile last switched to, which later runs use.
+func switched() (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(root, activeFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if name := strings.TrimSpace(string(data)); name != "" {
+		return name, nil
+	}
+	return DefaultProfile, nil
+}
+
+func profileDir(name string, create bool) (string, error) {
+	root, err := Root()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return root, nil
+	}
+	if !profileName.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+
+	dir := filepath.Join(root, profilesDir, name)
+	if create {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func checkExists(name string) error {
+	if name == DefaultProfile {
+		return nil
+	}
+	dir, err := profileDir(name, false)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("profile %q does not exist (create it with \"typ0 profile create %s\")", name, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Profiles lists every profile, the default one first.
+func Profiles() ([]string, error) {
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, profilesDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && profileName.MatchString(entry.Name()) && entry.Name() != DefaultProfile {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+func CreateProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if err := checkExists(name); err == nil {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	_, err := profileDir(name, true)
+	return err
+}
+
+// SwitchProfile makes name the active profile for later runs.
+func SwitchProfile(name string) error {
+	if err := checkExists(name); err != nil {
+		return err
+	}
+	root, err := Root()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, activeFile), []byte(name+"\n"), 0o644)
+}
+
+// DeleteProfile removes a profile and all of its data. The default profile
+// and one selected for this run with --profile or $TYP0_PROFILE cannot be
+// deleted; deleting the one switched to switches back to the default.
+func DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("the default profile cannot be deleted")
+	}
+	if err := checkExists(name); err != nil {
+		return err
+	}
+	if overridden() == name {
+		return fmt.Errorf("profile %q is in use through --profile or $TYP0_PROFILE; run without them to delete it", name)
+	}
+	dir, err := profileDir(name, false)
+	if err != nil {
+		return err
+	}
+
+	current, err := switched()
+	if err != nil {
+		return err
+	}
+	if current == name {
+		if err := SwitchProfile(DefaultProfile); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
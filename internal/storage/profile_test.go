@@ -0,0 +1,87 @@
+package storage
+
+import "testing"
+
+func setup(t *testing.T) {
+	t.Helper()
+	t.Setenv("TYP0_HOME", t.TempDir())
+	t.Setenv("TYP0_PROFILE", "")
+	override = ""
+	t.Cleanup(func() { override = "" })
+	for _, name := range []string{"work", "split"} {
+		if err := CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		switched string
+		env      string
+		flag     string
+		delete   string
+		wantErr  bool
+		// after is the profile switched to once the deletion is done.
+		after string
+	}{
+		{name: "default", delete: DefaultProfile, wantErr: true, after: DefaultProfile},
+		{name: "missing", delete: "home", wantErr: true, after: DefaultProfile},
+		{name: "other", switched: "work", delete: "split", after: "work"},
+		{name: "switched to", switched: "work", delete: "work", after: DefaultProfile},
+		{name: "overridden by flag", switched: "split", flag: "work", delete: "work", wantErr: true, after: "split"},
+		{name: "overridden by environment", switched: "split", env: "work", delete: "work", wantErr: true, after: "split"},
+		{name: "switched to while overridden", switched: "work", flag: "split", delete: "work", after: DefaultProfile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t)
+			if tt.switched != "" {
+				if err := SwitchProfile(tt.switched); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("TYP0_PROFILE", tt.env)
+			if tt.flag != "" {
+				if err := UseProfile(tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := DeleteProfile(tt.delete)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteProfile(%q) error = %v, want error %v", tt.delete, err, tt.wantErr)
+			}
+			if got, err := switched(); err != nil || got != tt.after {
+				t.Errorf("switched to %q (%v), want %q", got, err, tt.after)
+			}
+			if !tt.wantErr && checkExists(tt.delete) == nil {
+				t.Errorf("profile %q still exists", tt.delete)
+			}
+		})
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	setup(t)
+	if got, _ := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("ActiveProfile = %q, want %q", got, DefaultProfile)
+	}
+	if err := SwitchProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ActiveProfile(); got != "work" {
+		t.Errorf("after switch ActiveProfile = %q, want work", got)
+	}
+	t.Setenv("TYP0_PROFILE", "split")
+	if got, _ := ActiveProfile(); got != "split" {
+		t.Errorf("with $TYP0_PROFILE ActiveProfile = %q, want split", got)
+	}
+	if err := UseProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("with --profile ActiveProfile = %q, want %q", got, DefaultProfile)
+	}
+}
@@ -11,6 +11,7 @@ type Styles struct {
 	LabelStyle         lipgloss.Style
 	ValueStyle         lipgloss.Style
 	MistypedKeyStyle   lipgloss.Style
+	DimStyle           lipgloss.Style
 }
 
 func NewStyles() *Styles {
@@ -44,5 +45,8 @@ func NewStyles() *Styles {
 		MistypedKeyStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("1")).
 			Bold(true),
+
+		DimStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")),
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,216 @@
+package words
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A language pack lives in a directory named after its language code and
+// holds two plain text files:
+//
+//	words.txt   whitespace separated words
+//	quotes.txt  one quote per line
+//
+// Lines starting with '#' are comments, except "# name: <display name>",
+// which sets the pack's display name. User packs use the same layout and
+// take precedence over the embedded ones.
+
+//go:embed packs
+var embedded embed.FS
+
+const DefaultLang = "en"
+
+type Pack struct {
+	Code   string
+	Name   string
+	Words  []string
+	Quotes []string
+}
+
+var ErrUnknownLang = errors.New("unknown language")
+
+// builtinWords holds packs whose word list is compiled in rather than
+// embedded as a text file.
+var builtinWords = map[string][]string{
+	"en": Words,
+}
+
+// UserDirs returns the directories searched for user supplied packs, in
+// order of precedence.
+func UserDirs(extra ...string) []string {
+	dirs := append([]string{}, extra...)
+	if env := os.Getenv("TYP0_LANG_DIR"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	if config, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(config, "typ0", "langs"))
+	}
+	return dirs
+}
+
+// Load returns the pack for code, looking in dirs before the embedded packs.
+func Load(code string, dirs ...string) (*Pack, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		code = DefaultLang
+	}
+
+	for _, dir := range dirs {
+		pack, err := loadFS(os.DirFS(dir), code)
+		if err == nil {
+			return pack, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	sub, err := fs.Sub(embedded, "packs")
+	if err != nil {
+		return nil, err
+	}
+	pack, err := loadFS(sub, code)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownLang, code, strings.Join(Available(dirs...), ", "))
+	}
+	return pack, err
+}
+
+// Resolve loads the pack for lang, searching langDir and the user
+// directories. An empty lang is taken from the environment and falls back
+// to English when no such pack exists.
+func Resolve(lang, langDir string) (*Pack, error) {
+	var extra []string
+	if langDir != "" {
+		extra = append(extra, langDir)
+	}
+	dirs := UserDirs(extra...)
+
+	if lang != "" {
+		return Load(lang, dirs...)
+	}
+
+	pack, err := Load(LangFromEnv(), dirs...)
+	if err != nil {
+		return Load(DefaultLang, dirs...)
+	}
+	return pack, nil
+}
+
+// Available lists the language codes found in dirs and the embedded packs.
+func Available(dirs ...string) []string {
+	seen := make(map[string]bool)
+	collect := func(fsys fs.FS) {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				seen[entry.Name()] = true
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		collect(os.DirFS(dir))
+	}
+	if sub, err := fs.Sub(embedded, "packs"); err == nil {
+		collect(sub)
+	}
+
+	var codes []string
+	for code := range seen {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// LangFromEnv derives a language code from the locale environment, e.g.
+// "de_DE.UTF-8" becomes "de". It falls back to DefaultLang.
+func LangFromEnv() string {
+	for _, key := range []string{"LC_ALL", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		code := strings.ToLower(value)
+		if i := strings.IndexAny(code, "_.@-"); i >= 0 {
+			code = code[:i]
+		}
+		if code == "c" || code == "posix" || code == "" {
+			return DefaultLang
+		}
+		return code
+	}
+	return DefaultLang
+}
+
+func loadFS(fsys fs.FS, code string) (*Pack, error) {
+	info, err := fs.Stat(fsys, code)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fs.ErrNotExist
+	}
+
+	pack := &Pack{Code: code, Name: code, Words: builtinWords[code]}
+
+	if err := readPackFile(fsys, code+"/words.txt", pack, func(line string) {
+		pack.Words = append(pack.Words, strings.Fields(line)...)
+	}); err != nil {
+		return nil, err
+	}
+	if err := readPackFile(fsys, code+"/quotes.txt", pack, func(line string) {
+		pack.Quotes = append(pack.Quotes, line)
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(pack.Words) == 0 {
+		return nil, fmt.Errorf("language pack %q has no words", code)
+	}
+	return pack, nil
+}
+
+func readPackFile(fsys fs.FS, name string, pack *Pack, add func(line string)) error {
+	f, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return scanPackLines(f, pack, add)
+}
+
+func scanPackLines(r io.Reader, pack *Pack, add func(line string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			if name, ok := strings.CutPrefix(comment, "name:"); ok {
+				pack.Name = strings.TrimSpace(name)
+			}
+			continue
+		}
+		add(line)
+	}
+	return scanner.Err()
+}